@@ -4,23 +4,32 @@ import "github.com/maxshaw/orm/qb"
 
 type Builder struct {
 	executor Executor
+	dialect  Dialect
+	logger   Logger
 
 	table string
 
 	args  []any
 	exprs []qb.Expr
 
-	cols []string
+	cols    []string
+	selects []qb.Expr
 
 	offset, limit int
 
-	order, group string
+	order qb.Expr
+	group string
 
 	having []qb.Expr
 
-	joins []string
+	windows []namedWindow
+	qualify []qb.Expr
+
+	joins []qb.Expr
+
+	returning []string
 }
 
 func NewBuilder(executor Executor, table string) *Builder {
-	return (&Builder{executor: executor, table: table}).reset()
+	return (&Builder{executor: executor, dialect: DialectOf(executor), logger: LoggerOf(executor), table: table}).reset()
 }
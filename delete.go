@@ -0,0 +1,41 @@
+package orm
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/maxshaw/orm/qb"
+)
+
+func (b *Builder) Delete() (string, []any, error) {
+	cond, args, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.exprs...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cond == "" {
+		return "", nil, errors.New("not allow deleting rows with no where conditions")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("DELETE FROM ")
+	sb.WriteString(qb.Quote(b.table, ""))
+	sb.WriteString(" WHERE ")
+	sb.WriteString(b.limitWhere(cond))
+
+	b.args = append(b.args, args...)
+
+	if b.limit > 0 && b.dialect == MySQL {
+		sb.WriteString(" LIMIT ")
+		sb.WriteString(strconv.Itoa(b.limit))
+	}
+
+	sq := qb.Rebind(b.dialect, sb.String())
+
+	b.logger.Printf("[SQL] %s\n", sq)
+	b.logger.Printf("[SQL] %+v\n", b.args)
+
+	return sq, b.args, nil
+}
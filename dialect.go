@@ -0,0 +1,12 @@
+package orm
+
+import "github.com/maxshaw/orm/qb"
+
+// Dialect is the SQL flavour builders render, see qb.Dialect.
+type Dialect = qb.Dialect
+
+const (
+	MySQL    = qb.MySQL
+	Postgres = qb.Postgres
+	SQLite   = qb.SQLite
+)
@@ -1,5 +1,10 @@
 package orm
 
+import (
+	"errors"
+	"fmt"
+)
+
 type ValidationError struct {
 	Field, Msg string
 	Underlying error
@@ -8,3 +13,21 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Msg
 }
+
+// ErrStaleObject matches every *StaleObjectError with errors.Is.
+var ErrStaleObject = errors.New("stale object")
+
+// StaleObjectError reports a versioned update that changed no rows, because
+// the row was updated or removed since Version was read.
+type StaleObjectError struct {
+	Table   string
+	Version any
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("stale object: %s at version %v was changed or removed", e.Table, e.Version)
+}
+
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
@@ -0,0 +1,183 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlanStep is one row of a query plan. Columns keeps the raw output, the
+// other fields are filled where the dialect reports them.
+type PlanStep struct {
+	Table  string
+	Access string
+	Key    string
+	Rows   int64
+	Detail string
+
+	Columns map[string]string
+}
+
+// FullScan reports whether the step reads a whole table.
+func (s PlanStep) FullScan() bool {
+	return s.Access == "ALL" ||
+		strings.HasPrefix(s.Detail, "SCAN ") ||
+		strings.Contains(s.Detail, "Seq Scan") ||
+		strings.Contains(s.Detail, "Table scan")
+}
+
+type Plan struct {
+	Dialect Dialect
+	Query   string
+	Args    []any
+	Steps   []PlanStep
+
+	// Text is the plan as printed, one line per step for textual plans.
+	Text string
+}
+
+func (p *Plan) FullScan() bool {
+	for _, s := range p.Steps {
+		if s.FullScan() {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain builds the SELECT like ToSQL, without resetting the builder, and
+// returns its plan. With analyze the statement is actually executed, use it
+// with care on writes.
+func (b *Builder) Explain(analyze bool) (*Plan, error) {
+	sq, args, err := b.selectSQL()
+	if err != nil {
+		return nil, err
+	}
+	return Explain(b.executor, b.dialect, sq, args, analyze)
+}
+
+// Explain runs EXPLAIN for query in dialect and parses the output.
+func Explain(e Executor, dialect Dialect, query string, args []any, analyze bool) (*Plan, error) {
+	if e == nil {
+		return nil, errors.New("explain requires an executor")
+	}
+
+	var prefix string
+	switch {
+	case dialect == SQLite:
+		// SQLite has no ANALYZE form, the plan is all it reports
+		prefix = "EXPLAIN QUERY PLAN "
+	case analyze:
+		prefix = "EXPLAIN ANALYZE "
+	default:
+		prefix = "EXPLAIN "
+	}
+
+	rows, err := e.Query(prefix+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []string
+	defer func() { ReportRows(rows, int64(len(lines))) }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Dialect: dialect, Query: query, Args: args}
+
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		dest := make([]any, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		step := PlanStep{Columns: make(map[string]string, len(cols))}
+		for i, col := range cols {
+			step.Columns[col] = values[i].String
+		}
+
+		switch {
+		case len(cols) == 1:
+			// textual plans: Postgres, and MySQL EXPLAIN ANALYZE or FORMAT=TREE
+			step.Detail = values[0].String
+		case dialect == SQLite:
+			step.Detail = step.Columns["detail"]
+		default:
+			step.Table = step.Columns["table"]
+			step.Access = step.Columns["type"]
+			step.Key = step.Columns["key"]
+			step.Detail = step.Columns["Extra"]
+			step.Rows, _ = strconv.ParseInt(step.Columns["rows"], 10, 64)
+		}
+
+		lines = append(lines, step.Detail)
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	plan.Text = strings.Join(lines, "\n")
+	return plan, nil
+}
+
+type SlowPlan struct {
+	Query   string
+	Args    []any
+	Elapsed time.Duration
+
+	Plan *Plan
+	Err  error
+}
+
+// CaptureSlowPlans is an Interceptor explaining every statement that takes at
+// least threshold, and passing the plan to fn. Plans are fetched through
+// explainer, normally the bare *sql.DB, so that a statement whose rows are
+// still open does not block its own connection. fn runs synchronously.
+func CaptureSlowPlans(explainer Executor, dialect Dialect, threshold time.Duration, fn func(SlowPlan)) Interceptor {
+	return func(next Executor) Executor {
+		return &slowPlanExecutor{next: next, explainer: explainer, dialect: dialect, threshold: threshold, fn: fn}
+	}
+}
+
+type slowPlanExecutor struct {
+	next, explainer Executor
+
+	dialect   Dialect
+	threshold time.Duration
+	fn        func(SlowPlan)
+}
+
+func (e *slowPlanExecutor) Exec(query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := e.next.Exec(query, args...)
+	e.capture(query, args, time.Since(start))
+	return res, err
+}
+
+func (e *slowPlanExecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := e.next.Query(query, args...)
+	e.capture(query, args, time.Since(start))
+	return rows, err
+}
+
+func (e *slowPlanExecutor) capture(query string, args []any, elapsed time.Duration) {
+	if elapsed < e.threshold || e.fn == nil {
+		return
+	}
+
+	plan, err := Explain(e.explainer, e.dialect, query, args, false)
+	e.fn(SlowPlan{Query: query, Args: args, Elapsed: elapsed, Plan: plan, Err: err})
+}
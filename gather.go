@@ -0,0 +1,234 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Gather runs the SELECT of b over n tables or shards and merges the rows
+// as the single query would: ordered by the column of OrderBy, with the
+// offset and limit of b applied to the whole. Every fetch gets a copy of b
+// reading enough rows from the start, to be retargeted at target i. Raw and
+// computed orders cannot be merged and fail over more than one target.
+func Gather[T any](b *Builder, n int, fetch func(i int, page *Builder) ([]T, error)) ([]T, error) {
+	if n == 1 {
+		return fetch(0, b.Target(b.executor, b.table))
+	}
+
+	o, ordered := b.order.(orderExpr)
+	if b.order != nil && (!ordered || o.col == "") {
+		return nil, errors.New("raw and computed orders cannot be merged across tables")
+	}
+
+	offset := b.offset
+	if offset < 0 || b.limit < 0 {
+		offset = 0
+	}
+
+	var items []T
+	for i := 0; i < n; i++ {
+		page := b.Target(b.executor, b.table)
+		if b.limit > -1 {
+			page.Offset(-1).Limit(offset + b.limit)
+		}
+
+		part, err := fetch(i, page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, part...)
+	}
+
+	if b.order != nil {
+		if err := sortByColumn(items, o.col, o.suffix == " DESC", b.dialect == Postgres); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.limit > -1 {
+		if offset > len(items) {
+			offset = len(items)
+		}
+		items = items[offset:]
+		if b.limit < len(items) {
+			items = items[:b.limit]
+		}
+	}
+	return items, nil
+}
+
+// sortByColumn stable-sorts items, pointers to structs, by the field of col,
+// the first part of its db tag or its lowercased name. Nil values sort as the
+// smallest, or as the largest with nullsLast like on Postgres.
+func sortByColumn[T any](items []T, col string, desc, nullsLast bool) error {
+	if len(items) < 2 {
+		return nil
+	}
+
+	typ := reflect.TypeOf(items[0])
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot order %s by column", typ)
+	}
+
+	col = col[strings.LastIndex(col, ".")+1:]
+	index := -1
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("db"), ";")
+		if name == col || name == "" && strings.ToLower(f.Name) == col {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%s has no field for column %s", typ, col)
+	}
+
+	var err error
+	sort.SliceStable(items, func(i, j int) bool {
+		c, cerr := compareValues(field(items[i], index), field(items[j], index), nullsLast)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return err
+}
+
+func field(item any, index int) any {
+	return reflect.Indirect(reflect.ValueOf(item)).Field(index).Interface()
+}
+
+// compareValues compares column values the way databases order them, nil
+// before everything else unless nullsLast.
+func compareValues(a, b any, nullsLast bool) (int, error) {
+	a, err := plainValue(a)
+	if err != nil {
+		return 0, err
+	}
+	b, err = plainValue(b)
+	if err != nil {
+		return 0, err
+	}
+
+	null := -1
+	if nullsLast {
+		null = 1
+	}
+
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return null, nil
+	case b == nil:
+		return -null, nil
+	}
+
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return compareOrdered(x, y), nil
+		case float64:
+			return compareOrdered(float64(x), y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return compareOrdered(x, y), nil
+		case int64:
+			return compareOrdered(x, float64(y)), nil
+		}
+	case uint64:
+		if y, ok := b.(uint64); ok {
+			return compareOrdered(x, y), nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y), nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+func compareOrdered[T int64 | uint64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// plainValue reduces v to nil, int64, uint64, float64, string, []byte or
+// time.Time, booleans becoming 0 and 1.
+func plainValue(v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			return nil, nil
+		}
+		return plainValue(val)
+	}
+
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		return plainValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+	}
+
+	if rv.Type().ConvertibleTo(timeType) {
+		return rv.Convert(timeType).Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot order by %T", v)
+}
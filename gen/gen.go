@@ -47,7 +47,17 @@ type fieldDefault struct {
 type field struct {
 	Name, Column, Type string
 
+	JSON, FullText, Shard, SoftDelete, Version, Tenant bool
+
 	Default *fieldDefault
+
+	// Keys are the paths into a types.JSON[T] column of a struct T declared
+	// with the models.
+	Keys []jsonKey
+}
+
+type jsonKey struct {
+	Name, Path string
 }
 
 type modelPK struct {
@@ -64,6 +74,12 @@ type model struct {
 	Fields    []field
 	Relations map[string]*relation
 	Validates []string
+	FullText  []string
+	Shard     *field
+
+	SoftDelete *field
+	Version    *field
+	Tenant     *field
 }
 
 var (
@@ -224,7 +240,10 @@ func Gen() error {
 		_ = os.Mkdir(targetPath, 0777)
 	}
 
-	var models = make(map[string]*model, len(pkg.GoFiles))
+	var (
+		models  = make(map[string]*model, len(pkg.GoFiles))
+		structs = make(map[string]*ast.StructType)
+	)
 
 	for _, file := range pkg.GoFiles {
 		src, err := os.ReadFile(file)
@@ -244,6 +263,7 @@ func Gen() error {
 					if ts, ok := spec.(*ast.TypeSpec); ok {
 						if st, ok := ts.Type.(*ast.StructType); ok {
 							models[ts.Name.Name] = parse(newModel(f.Imports, ts.Name.Name), st)
+							structs[ts.Name.Name] = st
 						}
 					}
 				}
@@ -269,6 +289,12 @@ func Gen() error {
 			continue
 		}
 
+		for i, f := range model.Fields {
+			if f.JSON {
+				model.Fields[i].Keys = jsonKeys(structs, jsonElem(f.Type), "$", "", map[string]bool{})
+			}
+		}
+
 		for _, rel := range model.Relations {
 			if rel.FirstField == "" || rel.SecondField == "" {
 				if exist, ok := lo.Find(model.Fields, func(f field) bool { return f.Column == rel.First }); ok {
@@ -316,6 +342,7 @@ func Gen() error {
 		execTpl(t, name, "model", data)
 		execTpl(t, name+"query", "query", data)
 		execTpl(t, name+"update", "update", data)
+		execTpl(t, name+"delete", "delete", data)
 	}
 
 	return nil
@@ -351,14 +378,35 @@ FL:
 		// fmt.Printf("%s: %s\n", sf.Names[0].Name, typeName(m, sf.Type, "", false))
 
 		f := field{Name: sf.Names[0].Name, Type: typeName(m, sf.Type, "", false)}
+		f.JSON = strings.HasPrefix(strings.TrimPrefix(f.Type, "*"), "types.JSON[")
 
 		if sf.Tag != nil {
 			if tag := reflect.StructTag(strings.Trim(sf.Tag.Value, "`")).Get("db"); tag == "-" {
 				continue
 			} else {
-				for _, part := range strings.Split(tag, ";") {
+				for i, part := range strings.Split(tag, ";") {
 					if !strings.Contains(part, "=") {
-						f.Column = part
+						// the first part always names the column, so flags can be
+						// column names too, e.g. db:"version;version"
+						if i == 0 {
+							f.Column = part
+							continue
+						}
+
+						switch part {
+						case "fulltext":
+							f.FullText = true
+						case "shard":
+							f.Shard = true
+						case "softdelete":
+							f.SoftDelete = true
+						case "version":
+							f.Version = true
+						case "tenant":
+							f.Tenant = true
+						default:
+							f.Column = part
+						}
 						continue
 					}
 
@@ -520,6 +568,30 @@ if {{range $i, $v := .Value}}{{if gt $i 0}} && {{end}}{{ $.UnwrappedField }} !=
 			f.Column = strings.ToLower(f.Name)
 		}
 
+		if f.FullText {
+			m.FullText = append(m.FullText, f.Column)
+		}
+
+		if f.Shard && m.Shard == nil {
+			shard := f
+			m.Shard = &shard
+		}
+
+		if f.SoftDelete && m.SoftDelete == nil {
+			softDelete := f
+			m.SoftDelete = &softDelete
+		}
+
+		if f.Version && m.Version == nil {
+			version := f
+			m.Version = &version
+		}
+
+		if f.Tenant && m.Tenant == nil {
+			tenant := f
+			m.Tenant = &tenant
+		}
+
 		m.Fields = append(m.Fields, f)
 	}
 
@@ -540,6 +612,71 @@ if {{range $i, $v := .Value}}{{if gt $i 0}} && {{end}}{{ $.UnwrappedField }} !=
 	return m
 }
 
+// jsonElem is the name of T in types.JSON[T], without the models package.
+func jsonElem(typ string) string {
+	typ = strings.TrimPrefix(typ, "*")
+	typ = strings.TrimSuffix(strings.TrimPrefix(typ, "types.JSON["), "]")
+	return strings.TrimPrefix(strings.TrimPrefix(typ, "*"), modelsPkgName+".")
+}
+
+// jsonKeys lists the paths to the exported fields of struct typ and of the
+// structs nested in it, keyed like encoding/json does.
+func jsonKeys(structs map[string]*ast.StructType, typ, path, name string, seen map[string]bool) []jsonKey {
+	st, ok := structs[typ]
+	if !ok || seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	var keys []jsonKey
+	for _, sf := range st.Fields.List {
+		var tag string
+		if sf.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(sf.Tag.Value, "`")).Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		var elem string
+		switch x := sf.Type.(type) {
+		case *ast.Ident:
+			elem = x.Name
+		case *ast.StarExpr:
+			elem = ident(x.X)
+		}
+
+		for _, n := range sf.Names {
+			if !n.IsExported() {
+				continue
+			}
+
+			key, _, _ := strings.Cut(tag, ",")
+			if key == "" {
+				key = n.Name
+			}
+			if !isIdent(key) {
+				key = `"` + key + `"`
+			}
+
+			k := jsonKey{Name: name + n.Name, Path: path + "." + key}
+			keys = append(keys, k)
+			keys = append(keys, jsonKeys(structs, elem, k.Path, k.Name, seen)...)
+		}
+	}
+	return keys
+}
+
+func isIdent(s string) bool {
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
 func firstFieldName(l *ast.FieldList) string {
 	if l.NumFields() > 0 {
 		if id, ok := l.List[0].Type.(*ast.Ident); ok {
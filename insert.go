@@ -1,7 +1,6 @@
 package orm
 
 import (
-	"log"
 	"strings"
 
 	"github.com/maxshaw/orm/qb"
@@ -11,6 +10,13 @@ func (b *Builder) Insert(value qb.H) (string, []any, error) {
 	return b.InsertMulti([]qb.H{value})
 }
 
+// Returning makes the INSERT return cols of the inserted rows, for drivers
+// without LastInsertId such as Postgres. MySQL does not support it.
+func (b *Builder) Returning(cols ...string) *Builder {
+	b.returning = cols
+	return b
+}
+
 func (b *Builder) InsertMulti(values []qb.H) (string, []any, error) {
 	var sb strings.Builder
 
@@ -63,10 +69,20 @@ func (b *Builder) InsertMulti(values []qb.H) (string, []any, error) {
 		}
 	}
 
-	sq := sb.String()
+	if len(b.returning) > 0 {
+		sb.WriteString(" RETURNING ")
+		for i, col := range b.returning {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(qb.Quote(col, ""))
+		}
+	}
+
+	sq := qb.Rebind(b.dialect, sb.String())
 
-	log.Printf("[SQL] %s\n", sq)
-	log.Printf("[SQL] %+v\n", b.args)
+	b.logger.Printf("[SQL] %s\n", sq)
+	b.logger.Printf("[SQL] %+v\n", b.args)
 
 	return sq, b.args, nil
 }
@@ -0,0 +1,14 @@
+package orm
+
+// Interceptor wraps an Executor to observe or alter every statement sent
+// through it.
+type Interceptor func(next Executor) Executor
+
+// Intercept wraps e so that statements pass through interceptors in order,
+// the first one being the outermost.
+func Intercept(e Executor, interceptors ...Interceptor) Executor {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		e = interceptors[i](e)
+	}
+	return e
+}
@@ -0,0 +1,189 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// unsafeMark prefixes every interpolated statement.
+const unsafeMark = "/* interpolated for debugging, do not execute */ "
+
+// Interpolate builds the SELECT like ToSQL and inlines its arguments, for
+// logs and EXPLAIN in a console. Unlike ToSQL it neither logs nor resets the
+// builder. The result is NOT safe to execute.
+func (b *Builder) Interpolate() (string, error) {
+	sq, args, err := b.selectSQL()
+	if err != nil {
+		return "", err
+	}
+	return InterpolateSQL(sq, args, b.dialect)
+}
+
+// InterpolateSQL replaces the placeholders of query with args rendered as
+// literals of dialect. It exists for reading statements only: the escaping
+// is best effort and the result is NOT safe to execute.
+func InterpolateSQL(query string, args []any, dialect Dialect) (string, error) {
+	var (
+		sb    strings.Builder
+		quote rune
+		n     int
+	)
+
+	sb.WriteString(unsafeMark)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case quote != 0:
+			if rune(c) == quote {
+				quote = 0
+			} else if c == '\\' && dialect == MySQL && i+1 < len(query) {
+				sb.WriteByte(c)
+				i++
+				c = query[i]
+			}
+
+		case c == '\'' || c == '"' || c == '`':
+			quote = rune(c)
+
+		case c == '?':
+			if n >= len(args) {
+				return "", fmt.Errorf("missing argument for placeholder %d", n+1)
+			}
+			lit, err := literal(args[n], dialect)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(lit)
+			n++
+			continue
+
+		case c == '$' && dialect == Postgres:
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				idx, _ := strconv.Atoi(query[i+1 : j])
+				if idx < 1 || idx > len(args) {
+					return "", fmt.Errorf("missing argument for placeholder $%d", idx)
+				}
+				lit, err := literal(args[idx-1], dialect)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(lit)
+				if idx > n {
+					n = idx
+				}
+				i = j - 1
+				continue
+			}
+		}
+
+		sb.WriteByte(c)
+	}
+
+	if n < len(args) {
+		return "", fmt.Errorf("%d arguments for %d placeholders", len(args), n)
+	}
+
+	return sb.String(), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func literal(v any, dialect Dialect) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL", nil
+		}
+
+		val, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+
+		// valuers such as types.JSON hand over text as bytes
+		if b, ok := val.([]byte); ok && b != nil && utf8.Valid(b) {
+			return quoteString(string(b), dialect), nil
+		}
+		v = val
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "NULL", nil
+
+	case string:
+		return quoteString(x, dialect), nil
+
+	case []byte:
+		if x == nil {
+			return "NULL", nil
+		}
+		if dialect == Postgres {
+			return `'\x` + hex.EncodeToString(x) + `'::bytea`, nil
+		}
+		return "X'" + hex.EncodeToString(x) + "'", nil
+
+	case time.Time:
+		return quoteString(x.Format("2006-01-02 15:04:05.999999"), dialect), nil
+
+	case bool:
+		if dialect == Postgres {
+			return strings.ToUpper(strconv.FormatBool(x)), nil
+		}
+		if x {
+			return "1", nil
+		}
+		return "0", nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return literal(rv.Elem().Interface(), dialect)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+
+	case reflect.String:
+		return quoteString(rv.String(), dialect), nil
+
+	case reflect.Bool:
+		return literal(rv.Bool(), dialect)
+	}
+
+	if rv.Type().ConvertibleTo(timeType) {
+		return literal(rv.Convert(timeType).Interface(), dialect)
+	}
+
+	return quoteString(fmt.Sprint(v), dialect), nil
+}
+
+var mysqlEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+
+func quoteString(s string, dialect Dialect) string {
+	if dialect == MySQL {
+		return "'" + mysqlEscaper.Replace(s) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
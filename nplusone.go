@@ -0,0 +1,174 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NPlusOne reports a query repeated with different arguments in one scope.
+type NPlusOne struct {
+	Fingerprint string
+	Table       string
+	Count       int
+	Callers     []string
+
+	// Suggestion names the generated With eager-loads targeting Table.
+	Suggestion string
+}
+
+// NPlusOneDetector finds repeated, structurally identical queries that only
+// differ by their arguments, the trace of relations loaded row by row. It is
+// meant for development: statements are fingerprinted and remembered until
+// the end of each scope.
+//
+//	scope := detector.Scope()
+//	c := client.With(scope.Intercept) // per request
+//	...
+//	scope.Close()
+type NPlusOneDetector struct {
+	// Threshold is how many distinct argument sets make a query suspicious,
+	// 3 when zero.
+	Threshold int
+	OnDetect  func(NPlusOne)
+
+	// Dialect of the statements, see SlowQueryLog.Dialect.
+	Dialect Dialect
+}
+
+func (d *NPlusOneDetector) Scope() *NPlusOneScope {
+	return &NPlusOneScope{detector: d, seen: make(map[string]*repeated)}
+}
+
+type repeated struct {
+	args    map[string]struct{}
+	callers []string
+}
+
+type NPlusOneScope struct {
+	detector *NPlusOneDetector
+
+	mu   sync.Mutex
+	seen map[string]*repeated
+}
+
+// Intercept is an Interceptor recording the queries of the scope.
+func (s *NPlusOneScope) Intercept(next Executor) Executor {
+	return &nPlusOneExecutor{next: next, scope: s}
+}
+
+// Close reports the repeated queries of the scope to OnDetect and returns
+// them, most repeated first.
+func (s *NPlusOneScope) Close() []NPlusOne {
+	threshold := s.detector.Threshold
+	if threshold < 1 {
+		threshold = 3
+	}
+
+	s.mu.Lock()
+	var found []NPlusOne
+	for fp, r := range s.seen {
+		if len(r.args) < threshold {
+			continue
+		}
+
+		table := fromTable(fp)
+		found = append(found, NPlusOne{
+			Fingerprint: fp,
+			Table:       table,
+			Count:       len(r.args),
+			Callers:     r.callers,
+			Suggestion:  eagerLoadSuggestion(table),
+		})
+	}
+	s.seen = make(map[string]*repeated)
+	s.mu.Unlock()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Count > found[j].Count })
+
+	if s.detector.OnDetect != nil {
+		for _, n := range found {
+			s.detector.OnDetect(n)
+		}
+	}
+	return found
+}
+
+func (s *NPlusOneScope) record(query string, args []any) {
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		return
+	}
+
+	var (
+		fp     = FingerprintFor(s.detector.Dialect, query)
+		key    = fmt.Sprintf("%#v", args)
+		caller = callerOutside()
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.seen[fp]
+	if !ok {
+		r = &repeated{args: make(map[string]struct{})}
+		s.seen[fp] = r
+	}
+
+	r.args[key] = struct{}{}
+	for _, c := range r.callers {
+		if c == caller {
+			return
+		}
+	}
+	r.callers = append(r.callers, caller)
+}
+
+type nPlusOneExecutor struct {
+	next  Executor
+	scope *NPlusOneScope
+}
+
+func (e *nPlusOneExecutor) Exec(query string, args ...any) (sql.Result, error) {
+	return e.next.Exec(query, args...)
+}
+
+func (e *nPlusOneExecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	e.scope.record(query, args)
+	return e.next.Query(query, args...)
+}
+
+var fromRe = regexp.MustCompile("(?i)\\bFROM\\s+[`\"]?([\\w.]+)[`\"]?")
+
+func fromTable(query string) string {
+	if m := fromRe.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var (
+	eagerLoadsMu sync.RWMutex
+	eagerLoads   = make(map[string][]string)
+)
+
+// RegisterEagerLoad records that method eager-loads rows of table. Generated
+// code registers every With method so that NPlusOneDetector can suggest them.
+func RegisterEagerLoad(table, method string) {
+	eagerLoadsMu.Lock()
+	eagerLoads[table] = append(eagerLoads[table], method)
+	eagerLoadsMu.Unlock()
+}
+
+func eagerLoadSuggestion(table string) string {
+	eagerLoadsMu.RLock()
+	methods := eagerLoads[table]
+	eagerLoadsMu.RUnlock()
+
+	if len(methods) < 1 {
+		return ""
+	}
+	return "load " + table + " with " + strings.Join(methods, " or ") + " on the parent query"
+}
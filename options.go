@@ -0,0 +1,187 @@
+package orm
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+)
+
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type discard struct{}
+
+func (discard) Printf(string, ...any) {}
+
+// Discard is a Logger dropping everything, e.g. to silence [SQL] lines.
+var Discard Logger = discard{}
+
+// Options configure a generated client, see its Open.
+type Options struct {
+	// Driver is the database/sql driver name, "mysql" when empty. The
+	// application imports the driver package, e.g.
+	// _ "github.com/go-sql-driver/mysql".
+	Driver string
+
+	// DB is used as is instead of opening Driver.
+	DB *sql.DB
+
+	// Pool settings, applied when non-zero.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// Dialect defaults to the one of Driver. It selects identifier quoting,
+	// placeholders, LIMIT forms and dialect specific functions of the
+	// builders, as well as EXPLAIN forms and literal escaping.
+	Dialect Dialect
+
+	// Logger receives the [SQL] lines of builders, log.Default() when nil.
+	Logger Logger
+
+	Interceptors []Interceptor
+
+	// Sharding routes models declaring a shard key.
+	Sharding ShardResolver
+
+	// Tables names the table of a model per context, see WithContext on the
+	// generated accessors.
+	Tables TableResolver
+
+	dialectSet bool
+}
+
+type Option func(o *Options)
+
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.Driver == "" {
+		o.Driver = "mysql"
+	}
+
+	if !o.dialectSet {
+		o.Dialect = driverDialect(o.Driver)
+	}
+
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+
+	return o
+}
+
+// Open returns DB when set, otherwise opens dsn with Driver, and applies the
+// pool settings.
+func (o Options) Open(dsn string) (*sql.DB, error) {
+	db := o.DB
+	if db == nil {
+		var err error
+		if db, err = sql.Open(o.Driver, dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	if o.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+	if o.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+	}
+
+	return db, nil
+}
+
+func driverDialect(driver string) Dialect {
+	switch {
+	case strings.HasPrefix(driver, "sqlite"):
+		return SQLite
+	case driver == "postgres" || driver == "pgx" || driver == "cloudsqlpostgres":
+		return Postgres
+	default:
+		return MySQL
+	}
+}
+
+func WithDriver(name string) Option {
+	return func(o *Options) { o.Driver = name }
+}
+
+func WithDB(db *sql.DB) Option {
+	return func(o *Options) { o.DB = db }
+}
+
+func WithMaxOpenConns(n int) Option {
+	return func(o *Options) { o.MaxOpenConns = n }
+}
+
+func WithMaxIdleConns(n int) Option {
+	return func(o *Options) { o.MaxIdleConns = n }
+}
+
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(o *Options) { o.ConnMaxLifetime = d }
+}
+
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return func(o *Options) { o.ConnMaxIdleTime = d }
+}
+
+func WithDialect(d Dialect) Option {
+	return func(o *Options) { o.Dialect, o.dialectSet = d, true }
+}
+
+func WithLogger(l Logger) Option {
+	return func(o *Options) { o.Logger = l }
+}
+
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *Options) { o.Interceptors = append(o.Interceptors, interceptors...) }
+}
+
+func WithSharding(r ShardResolver) Option {
+	return func(o *Options) { o.Sharding = r }
+}
+
+func WithTableResolver(r TableResolver) Option {
+	return func(o *Options) { o.Tables = r }
+}
+
+// Bind attaches a dialect and logger to e, picked up by the builders created
+// on it with NewBuilder.
+func Bind(e Executor, dialect Dialect, logger Logger) Executor {
+	return &bound{Executor: e, dialect: dialect, logger: logger}
+}
+
+type bound struct {
+	Executor
+
+	dialect Dialect
+	logger  Logger
+}
+
+func DialectOf(e Executor) Dialect {
+	if b, ok := e.(*bound); ok {
+		return b.dialect
+	}
+	return MySQL
+}
+
+func LoggerOf(e Executor) Logger {
+	if b, ok := e.(*bound); ok && b.logger != nil {
+		return b.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,131 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TableResolver names the table a model uses within ctx, given the model's
+// own table name, e.g. the monthly partition of the request's date.
+type TableResolver func(ctx context.Context, table string) string
+
+// MonthlyPartition names the partition of table holding t, e.g. orders_2026_10.
+func MonthlyPartition(table string, t time.Time) string {
+	return table + t.Format("_2006_01")
+}
+
+// MonthlyPartitions names the partitions of table from the month of from to
+// the month of to, both included.
+func MonthlyPartitions(table string, from, to time.Time) []string {
+	var (
+		tables []string
+		month  = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		last   = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, from.Location())
+	)
+	for !month.After(last) {
+		tables = append(tables, MonthlyPartition(table, month))
+		month = month.AddDate(0, 1, 0)
+	}
+	return tables
+}
+
+// EnsureTable creates table with the schema of base unless it exists. On
+// SQLite the indexes of base are not copied.
+func EnsureTable(e Executor, base, table string) error {
+	if base == "" || table == "" {
+		return errors.New("ensure table requires a base and a table name")
+	}
+
+	var sq string
+	switch DialectOf(e) {
+	case Postgres:
+		sq = "CREATE TABLE IF NOT EXISTS " + pgIdent(table) + " (LIKE " + pgIdent(base) + " INCLUDING ALL)"
+	case SQLite:
+		def, err := sqliteSchema(e, base)
+		if err != nil {
+			return err
+		}
+		sq = "CREATE TABLE IF NOT EXISTS " + myIdent(table) + " " + def
+	default:
+		sq = "CREATE TABLE IF NOT EXISTS " + myIdent(table) + " LIKE " + myIdent(base)
+	}
+
+	LoggerOf(e).Printf("[SQL] %s\n", sq)
+
+	_, err := e.Exec(sq)
+	return err
+}
+
+// Partitions ensures partition tables on one database, once per table. It is
+// meant for the primary database of a client: DDL commits an open transaction
+// on MySQL, so partitions are not created on transactions. A table dropped
+// meanwhile is not noticed.
+type Partitions struct {
+	executor Executor
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func NewPartitions(e Executor) *Partitions {
+	return &Partitions{executor: e, done: make(map[string]bool)}
+}
+
+// Ensure runs EnsureTable for table unless it already succeeded.
+func (p *Partitions) Ensure(base, table string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done[table] {
+		return nil
+	}
+	if err := EnsureTable(p.executor, base, table); err != nil {
+		return err
+	}
+	p.done[table] = true
+	return nil
+}
+
+// sqliteSchema returns the column list of the CREATE TABLE statement base
+// was created with.
+func sqliteSchema(e Executor, base string) (string, error) {
+	rows, err := e.Query("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", base)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var (
+		def string
+		n   int64
+	)
+	defer func() { ReportRows(rows, n) }()
+
+	if rows.Next() {
+		n = 1
+		if err = rows.Scan(&def); err != nil {
+			return "", err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+
+	i := strings.Index(def, "(")
+	if i < 0 {
+		return "", fmt.Errorf("table %s does not exist", base)
+	}
+	return def[i:], nil
+}
+
+func myIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+func pgIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,108 @@
+package qb
+
+import (
+	"errors"
+	"strings"
+)
+
+type caseWhen struct {
+	cond Expr
+	val  any
+}
+
+// CaseExpr is a searched CASE expression, usable as a condition, in
+// Builder.SelectExpr and Builder.OrderByExpr, or as a value in Builder.Update:
+//
+//	qb.Case().When(qb.Eq("id", 1), "paid").When(qb.Eq("id", 2), "void").Else(qb.Col("status"))
+type CaseExpr struct {
+	whens []caseWhen
+
+	els    any
+	hasEls bool
+}
+
+func Case() *CaseExpr {
+	return &CaseExpr{}
+}
+
+func (c *CaseExpr) When(cond Expr, val any) *CaseExpr {
+	c.whens = append(c.whens, caseWhen{cond: cond, val: val})
+	return c
+}
+
+func (c *CaseExpr) Else(val any) *CaseExpr {
+	c.els, c.hasEls = val, true
+	return c
+}
+
+func (*CaseExpr) Sub() bool {
+	return false
+}
+
+func (c *CaseExpr) Build(table string) (string, []any, error) {
+	return c.BuildDialect(MySQL, table)
+}
+
+func (c *CaseExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	if len(c.whens) < 1 {
+		return "", nil, errors.New("CASE requires at least one WHEN")
+	}
+
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	sb.WriteString("CASE")
+	for _, w := range c.whens {
+		cond, condArgs, err := BuildFor(d, table, "AND", false, w.cond)
+		if err != nil {
+			return "", nil, err
+		}
+
+		val, valArgs, err := BindFor(d, table, w.val)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sb.WriteString(" WHEN ")
+		sb.WriteString(cond)
+		sb.WriteString(" THEN ")
+		sb.WriteString(val)
+		args = append(append(args, condArgs...), valArgs...)
+	}
+
+	if c.hasEls {
+		val, valArgs, err := BindFor(d, table, c.els)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sb.WriteString(" ELSE ")
+		sb.WriteString(val)
+		args = append(args, valArgs...)
+	}
+
+	sb.WriteString(" END")
+
+	return sb.String(), args, nil
+}
+
+// Bind renders v where a value is expected: expressions and column
+// references inline, anything else as a placeholder.
+func Bind(table string, v any) (string, []any, error) {
+	return BindFor(MySQL, table, v)
+}
+
+// BindFor is Bind for dialect d.
+func BindFor(d Dialect, table string, v any) (string, []any, error) {
+	switch x := v.(type) {
+	case Expr:
+		return BuildExpr(d, table, x)
+	case operand:
+		out, args := x.operand(d, table)
+		return out, args, nil
+	default:
+		return "?", []any{v}, nil
+	}
+}
@@ -0,0 +1,86 @@
+package qb
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Dialect int
+
+const (
+	MySQL Dialect = iota
+	Postgres
+	SQLite
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case SQLite:
+		return "sqlite"
+	default:
+		return "mysql"
+	}
+}
+
+// DialectExpr is implemented by expressions rendered differently per
+// dialect; their Build renders MySQL.
+type DialectExpr interface {
+	Expr
+	BuildDialect(d Dialect, table string) (string, []any, error)
+}
+
+// BuildExpr renders e for dialect d.
+func BuildExpr(d Dialect, table string, e Expr) (string, []any, error) {
+	if de, ok := e.(DialectExpr); ok {
+		return de.BuildDialect(d, table)
+	}
+	return e.Build(table)
+}
+
+// Rebind turns a statement rendered with backtick identifiers and ?
+// placeholders into the syntax of d. MySQL and SQLite take it as is; for
+// Postgres identifiers are double quoted and placeholders numbered $1, $2...
+// String literals and quoted identifiers are left alone.
+func Rebind(d Dialect, sq string) string {
+	if d != Postgres {
+		return sq
+	}
+
+	var (
+		sb strings.Builder
+		n  int
+	)
+	for i := 0; i < len(sq); i++ {
+		switch c := sq[i]; c {
+		case '\'', '"':
+			j := i + 1
+			for j < len(sq) && sq[j] != c {
+				j++
+			}
+			if j >= len(sq) {
+				j = len(sq) - 1
+			}
+			sb.WriteString(sq[i : j+1])
+			i = j
+
+		case '`':
+			j := strings.IndexByte(sq[i+1:], '`')
+			if j < 0 {
+				sb.WriteString(sq[i:])
+				return sb.String()
+			}
+			sb.WriteString(`"` + strings.ReplaceAll(sq[i+1:i+1+j], `"`, `""`) + `"`)
+			i += j + 1
+
+		case '?':
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
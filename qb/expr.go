@@ -12,10 +12,11 @@ type Expr interface {
 
 type WhereExpr struct {
 	col     string
+	cols    []string
 	args    []any
 	op, raw string
 
-	executor func(table string) (string, []any, error)
+	executor func(d Dialect, table string) (string, []any, error)
 }
 
 func (w WhereExpr) String() string {
@@ -26,23 +27,59 @@ func (w WhereExpr) Sub() bool {
 	return false
 }
 
+// Column is the column the condition tests, empty for raw conditions.
+func (w WhereExpr) Column() string {
+	return w.col
+}
+
+// Columns are all columns the condition tests, several for InTuple and Match.
+func (w WhereExpr) Columns() []string {
+	if len(w.cols) > 0 {
+		return w.cols
+	}
+	if w.col != "" {
+		return []string{w.col}
+	}
+	return nil
+}
+
+// Operator is the comparison, e.g. "=", "IN" or "IS NULL", empty for raw
+// conditions.
+func (w WhereExpr) Operator() string {
+	return w.op
+}
+
+// Args are the values compared against, before rendering.
+func (w WhereExpr) Args() []any {
+	return w.args
+}
+
 func (w WhereExpr) Build(table string) (cond string, args []any, err error) {
+	return w.BuildDialect(MySQL, table)
+}
+
+func (w WhereExpr) BuildDialect(d Dialect, table string) (cond string, args []any, err error) {
+	if w.executor != nil {
+		// operands such as Column among the values, e.g. In("a", Col("b"))
+		cond, args, err = w.executor(d, table)
+		if err != nil {
+			return "", nil, err
+		}
+		return bindOperands(d, table, cond, args)
+	}
+
 	if w.col != "" {
 		col := Quote(table, w.col)
 		if w.raw == "" {
-			return col + " " + w.op + " ?", w.args, nil
+			return bindOperands(d, table, col+" "+w.op+" ?", w.args)
 		}
-		return col + w.raw, w.args, nil
-	}
-
-	if w.executor != nil {
-		return w.executor(table)
+		return bindOperands(d, table, col+w.raw, w.args)
 	}
 
 	if w.raw == "" {
 		return "<not a valid expr>", nil, nil
 	}
-	return w.raw, w.args, nil
+	return bindOperands(d, table, w.raw, w.args)
 }
 
 type subExpr struct {
@@ -54,18 +91,139 @@ func (subExpr) Sub() bool {
 	return true
 }
 
+// Operator is "AND" or "OR".
+func (e subExpr) Operator() string {
+	return e.typ
+}
+
+func (e subExpr) Exprs() []Expr {
+	return e.exprs
+}
+
 func (e subExpr) Build(table string) (string, []any, error) {
-	return Build(table, e.typ, true, e.exprs...)
+	return e.BuildDialect(MySQL, table)
+}
+
+func (e subExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	return BuildFor(d, table, e.typ, true, e.exprs...)
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+func (notExpr) Sub() bool {
+	return false
+}
+
+func (notExpr) Operator() string {
+	return "NOT"
+}
+
+func (e notExpr) Exprs() []Expr {
+	return []Expr{e.expr}
+}
+
+func (e notExpr) Build(table string) (string, []any, error) {
+	return e.BuildDialect(MySQL, table)
+}
+
+func (e notExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	// built as the sole operand of an OR so nothing is parenthesised twice
+	cond, args, err := BuildFor(d, table, "OR", false, e.expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// an empty group holds for every row, so its negation holds for none
+	if cond == "" {
+		return "1 = 0", nil, nil
+	}
+	return "NOT (" + cond + ")", args, nil
+}
+
+// operand is rendered in place of a placeholder instead of being bound.
+type operand interface {
+	operand(d Dialect, table string) (string, []any)
+}
+
+// Column references a column where a comparison would otherwise bind a value,
+// e.g. qb.Gt("updated_at", qb.Col("created_at")).
+type Column string
+
+func Col(name string) Column {
+	return Column(name)
+}
+
+func (c Column) operand(_ Dialect, table string) (string, []any) {
+	return Quote(table, string(c)), nil
+}
+
+// bindOperands inlines operand arguments such as Column in place of their
+// placeholders.
+func bindOperands(d Dialect, table, sq string, args []any) (string, []any, error) {
+	var found bool
+	for _, arg := range args {
+		if _, ok := arg.(operand); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return sq, args, nil
+	}
+
+	var (
+		sb    strings.Builder
+		bound = make([]any, 0, len(args))
+		i     int
+	)
+	for _, c := range sq {
+		if c != '?' {
+			sb.WriteRune(c)
+			continue
+		}
+
+		if i >= len(args) {
+			return "", nil, fmt.Errorf("more placeholders than arguments in: %s", sq)
+		}
+
+		if op, ok := args[i].(operand); ok {
+			out, opArgs := op.operand(d, table)
+			sb.WriteString(out)
+			bound = append(bound, opArgs...)
+		} else {
+			sb.WriteRune(c)
+			bound = append(bound, args[i])
+		}
+		i++
+	}
+
+	if i != len(args) {
+		return "", nil, fmt.Errorf("more arguments than placeholders in: %s", sq)
+	}
+
+	return sb.String(), bound, nil
 }
 
 func Quote(a, b string) string {
 	if b == "" {
-		return fmt.Sprintf("`%s`", strings.Trim(a, "`"))
+		return quoteIdent(a)
 	}
 
 	if strings.Contains(b, ".") {
-		return "`" + strings.Trim(b, "`") + "`"
+		return quoteIdent(b)
 	}
 
-	return fmt.Sprintf("`%s`.`%s`", strings.Trim(a, "`"), strings.Trim(b, "`"))
+	return quoteIdent(a) + "." + quoteIdent(b)
+}
+
+// quoteIdent quotes every part of a possibly qualified name, so both
+// users.id and `users`.`id` become `users`.`id`.
+func quoteIdent(s string) string {
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		parts[i] = "`" + strings.Trim(part, "`") + "`"
+	}
+	return strings.Join(parts, ".")
 }
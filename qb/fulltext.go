@@ -0,0 +1,94 @@
+package qb
+
+import "strings"
+
+type MatchMode int
+
+const (
+	NaturalMode MatchMode = iota
+	BooleanMode
+	ExpansionMode
+)
+
+func (m MatchMode) String() string {
+	switch m {
+	case BooleanMode:
+		return "IN BOOLEAN MODE"
+	case ExpansionMode:
+		return "WITH QUERY EXPANSION"
+	default:
+		return "IN NATURAL LANGUAGE MODE"
+	}
+}
+
+// Match searches the full-text index of cols: a FULLTEXT index covering
+// exactly cols on MySQL, to_tsvector over cols on Postgres, and the FTS5
+// table the builder targets on SQLite. On MySQL it also yields the relevance
+// score in Builder.SelectExpr or Builder.OrderByExpr; use MatchScore for a
+// score in every dialect.
+func Match(cols []string, query string, mode MatchMode) Expr {
+	return WhereExpr{op: "MATCH", cols: cols, args: []any{query}, executor: func(d Dialect, table string) (string, []any, error) {
+		switch d {
+		case Postgres:
+			return tsVector(table, cols) + " @@ " + tsQuery(mode), []any{query}, nil
+		case SQLite:
+			return Quote(table, "") + " MATCH ?", []any{ftsQuery(cols, query, mode)}, nil
+		default:
+			return against(table, cols, mode), []any{query}, nil
+		}
+	}}
+}
+
+// MatchScore is the relevance of rows to a Match with the same arguments,
+// higher for better matches.
+func MatchScore(cols []string, query string, mode MatchMode) Expr {
+	return WhereExpr{op: "MATCH", cols: cols, args: []any{query}, executor: func(d Dialect, table string) (string, []any, error) {
+		switch d {
+		case Postgres:
+			return "ts_rank(" + tsVector(table, cols) + ", " + tsQuery(mode) + ")", []any{query}, nil
+		case SQLite:
+			// bm25 is lower for better matches
+			return "-bm25(" + Quote(table, "") + ")", nil, nil
+		default:
+			return against(table, cols, mode), []any{query}, nil
+		}
+	}}
+}
+
+func against(table string, cols []string, mode MatchMode) string {
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = Quote(table, col)
+	}
+	return "MATCH (" + strings.Join(quoted, ", ") + ") AGAINST (? " + mode.String() + ")"
+}
+
+func tsVector(table string, cols []string) string {
+	quoted := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = Quote(table, col)
+	}
+	return "to_tsvector(concat_ws(' ', " + strings.Join(quoted, ", ") + "))"
+}
+
+// tsQuery parses the query as typed text, or with web search syntax (quotes,
+// or, -) in BooleanMode. Postgres has no query expansion.
+func tsQuery(mode MatchMode) string {
+	if mode == BooleanMode {
+		return "websearch_to_tsquery(?)"
+	}
+	return "plainto_tsquery(?)"
+}
+
+// ftsQuery restricts an FTS5 query to cols. Outside BooleanMode every word
+// is quoted, so the query matches rows containing all of them.
+func ftsQuery(cols []string, query string, mode MatchMode) string {
+	if mode != BooleanMode {
+		words := strings.Fields(query)
+		for i, word := range words {
+			words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+		}
+		query = strings.Join(words, " ")
+	}
+	return "{" + strings.Join(cols, " ") + "} : (" + query + ")"
+}
@@ -0,0 +1,170 @@
+package qb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONPathExpr extracts the unquoted value at a JSON path of a column, with
+// JSON_EXTRACT on MySQL, ->> or #>> on Postgres and json_extract on SQLite.
+// Pass it as the value of any comparison, or compare it directly with its
+// methods:
+//
+//	qb.JSONPath("profile", "$.color").Eq("red")
+//	qb.Eq("color", qb.JSONPath("profile", "$.color"))
+type JSONPathExpr struct {
+	col, path string
+}
+
+func JSONPath(col, path string) JSONPathExpr {
+	return JSONPathExpr{col: col, path: jsonPath(path)}
+}
+
+func (p JSONPathExpr) operand(d Dialect, table string) (string, []any) {
+	col := Quote(table, p.col)
+	switch d {
+	case Postgres:
+		keys := pgPathKeys(p.path)
+		if len(keys) == 1 {
+			return col + "::jsonb ->> ?::text", []any{keys[0]}
+		}
+		return col + "::jsonb #>> ?::text[]", []any{pgTextArray(keys)}
+	case SQLite:
+		return "json_extract(" + col + ", ?)", []any{p.path}
+	default:
+		return "JSON_UNQUOTE(JSON_EXTRACT(" + col + ", ?))", []any{p.path}
+	}
+}
+
+func (p JSONPathExpr) compare(op string, val any) Expr {
+	return p.cond(op, "? "+op+" ?", p, val)
+}
+
+func (p JSONPathExpr) Eq(val any) Expr {
+	return p.compare("=", val)
+}
+
+func (p JSONPathExpr) Neq(val any) Expr {
+	return p.compare("<>", val)
+}
+
+func (p JSONPathExpr) Gt(val any) Expr {
+	return p.compare(">", val)
+}
+
+func (p JSONPathExpr) Lt(val any) Expr {
+	return p.compare("<", val)
+}
+
+func (p JSONPathExpr) Gte(val any) Expr {
+	return p.compare(">=", val)
+}
+
+func (p JSONPathExpr) Lte(val any) Expr {
+	return p.compare("<=", val)
+}
+
+func (p JSONPathExpr) Null() Expr {
+	return p.cond("IS NULL", "? IS NULL", p)
+}
+
+// cond reports the column of p to Walk and Columns, while the executor keeps
+// the condition from rendering as a comparison of the plain column.
+func (p JSONPathExpr) cond(op, raw string, args ...any) Expr {
+	return WhereExpr{col: p.col, op: op, raw: raw, args: args, executor: func(Dialect, string) (string, []any, error) {
+		return raw, args, nil
+	}}
+}
+
+// JSONContains matches documents containing val, marshaled to JSON.
+func JSONContains(col string, val any) Expr {
+	return JSONArrayContains(col, "$", val)
+}
+
+// JSONArrayContains matches documents whose value at path contains val, which
+// for an array means val is one of its elements. It renders JSON_CONTAINS on
+// MySQL and @> on Postgres; SQLite only supports scalar values, looked up
+// with json_each.
+func JSONArrayContains(col, path string, val any) Expr {
+	return WhereExpr{col: col, op: "JSON_CONTAINS", args: []any{val}, executor: func(d Dialect, table string) (string, []any, error) {
+		path := jsonPath(path)
+
+		if d == SQLite {
+			if !jsonScalar(val) {
+				return "", nil, fmt.Errorf("JSON containment of %T is not supported on sqlite", val)
+			}
+			return "EXISTS (SELECT 1 FROM json_each(" + Quote(table, col) + ", ?) WHERE value = ?)", []any{path, val}, nil
+		}
+
+		doc, err := json.Marshal(val)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if d == Postgres {
+			if path == "$" {
+				return Quote(table, col) + "::jsonb @> ?::jsonb", []any{string(doc)}, nil
+			}
+			return "(" + Quote(table, col) + "::jsonb #> ?::text[]) @> ?::jsonb", []any{pgTextArray(pgPathKeys(path)), string(doc)}, nil
+		}
+
+		return "JSON_CONTAINS(" + Quote(table, col) + ", ?, ?)", []any{string(doc), path}, nil
+	}}
+}
+
+// JSONHasKey matches documents having a value at path, e.g. "$.a.b" or "a.b".
+func JSONHasKey(col, path string) Expr {
+	return WhereExpr{col: col, op: "JSON_CONTAINS_PATH", args: []any{path}, executor: func(d Dialect, table string) (string, []any, error) {
+		switch d {
+		case Postgres:
+			return "jsonb_path_exists(" + Quote(table, col) + "::jsonb, ?::jsonpath)", []any{jsonPath(path)}, nil
+		case SQLite:
+			return "json_type(" + Quote(table, col) + ", ?) IS NOT NULL", []any{jsonPath(path)}, nil
+		default:
+			return "JSON_CONTAINS_PATH(" + Quote(table, col) + ", 'one', ?)", []any{jsonPath(path)}, nil
+		}
+	}}
+}
+
+func jsonScalar(v any) bool {
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Invalid:
+		return false
+	default:
+		return true
+	}
+}
+
+// pgPathKeys splits a JSON path such as $.a.b[0] into the keys of a Postgres
+// path array, a, b and 0.
+func pgPathKeys(path string) []string {
+	var keys []string
+	for _, part := range strings.FieldsFunc(strings.TrimPrefix(path, "$"), func(r rune) bool {
+		return r == '.' || r == '[' || r == ']'
+	}) {
+		keys = append(keys, strings.Trim(part, `"`))
+	}
+	return keys
+}
+
+var pgArrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func pgTextArray(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = `"` + pgArrayEscaper.Replace(key) + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+func jsonPath(path string) string {
+	if path == "" || path == "$" {
+		return "$"
+	}
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+	return "$." + path
+}
@@ -0,0 +1,99 @@
+package qb
+
+// Group is implemented by expressions combining others: And, Or and Not.
+type Group interface {
+	Expr
+	Operator() string
+	Exprs() []Expr
+}
+
+// Cond is implemented by single conditions such as WhereExpr. Column is
+// empty for conditions over several columns, which Columns lists.
+type Cond interface {
+	Expr
+	Column() string
+	Columns() []string
+	Operator() string
+	Args() []any
+}
+
+// Walk visits e and its children depth first, parents before children. The
+// children of a CaseExpr are its WHEN conditions and any expression values.
+// Returning false from fn skips the children of that expression.
+func Walk(e Expr, fn func(e Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+
+	switch x := e.(type) {
+	case Group:
+		for _, child := range x.Exprs() {
+			Walk(child, fn)
+		}
+
+	case *CaseExpr:
+		for _, w := range x.whens {
+			Walk(w.cond, fn)
+			if val, ok := w.val.(Expr); ok {
+				Walk(val, fn)
+			}
+		}
+		if els, ok := x.els.(Expr); ok {
+			Walk(els, fn)
+		}
+	}
+}
+
+// Rewrite rebuilds e bottom up, replacing every expression with fn's result.
+// Children rewritten to nil are dropped, and so are groups left empty.
+func Rewrite(e Expr, fn func(e Expr) Expr) Expr {
+	switch x := e.(type) {
+	case nil:
+		return nil
+
+	case subExpr:
+		exprs := make([]Expr, 0, len(x.exprs))
+		for _, child := range x.exprs {
+			if child = Rewrite(child, fn); child != nil {
+				exprs = append(exprs, child)
+			}
+		}
+		if len(exprs) < 1 {
+			return nil
+		}
+		return fn(subExpr{typ: x.typ, exprs: exprs})
+
+	case notExpr:
+		inner := Rewrite(x.expr, fn)
+		if inner == nil {
+			return nil
+		}
+		return fn(notExpr{expr: inner})
+
+	default:
+		return fn(e)
+	}
+}
+
+// Columns lists the distinct columns the expressions test, in order of
+// appearance.
+func Columns(a ...Expr) []string {
+	var (
+		cols []string
+		seen = make(map[string]struct{})
+	)
+	for _, e := range a {
+		Walk(e, func(e Expr) bool {
+			if c, ok := e.(Cond); ok {
+				for _, col := range c.Columns() {
+					if _, ok := seen[col]; !ok {
+						seen[col] = struct{}{}
+						cols = append(cols, col)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return cols
+}
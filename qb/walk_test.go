@@ -0,0 +1,38 @@
+package qb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	for _, tc := range []struct {
+		expr Expr
+		want []string
+	}{
+		{And(Eq("a", 1), Or(Eq("b", 2), Not(Eq("a", 3)))), []string{"a", "b"}},
+		{InTuple([]string{"a", "b"}, []any{1, 2}), []string{"a", "b"}},
+		{Case().When(Eq("tenant_id", 1), "x").Else(Case().When(Eq("b", 2), "y")), []string{"tenant_id", "b"}},
+		{JSONPath("tenant_id", "$.id").Eq(1), []string{"tenant_id"}},
+		{JSONPath("profile", "$.color").Null(), []string{"profile"}},
+		{Match([]string{"title", "body"}, "go", NaturalMode), []string{"title", "body"}},
+		{Raw("a = 1"), nil},
+	} {
+		if got := Columns(tc.expr); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%#v: got %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestJSONPathBuild(t *testing.T) {
+	got, args, err := Build("t", "AND", false, Eq("a", 1), JSONPath("p", "$.x").Gt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`t`.`a` = ? AND JSON_UNQUOTE(JSON_EXTRACT(`t`.`p`, ?)) > ?"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if want := []any{1, "$.x", 2}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %v, want %v", args, want)
+	}
+}
@@ -1,6 +1,8 @@
 package qb
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 )
@@ -30,7 +32,11 @@ func Lte(col string, val any) Expr {
 }
 
 func Between(col string, a, b any) Expr {
-	return WhereExpr{col: col, raw: " BETWEEN ? AND ?", args: []any{a, b}}
+	return WhereExpr{col: col, op: "BETWEEN", raw: " BETWEEN ? AND ?", args: []any{a, b}}
+}
+
+func NotBetween(col string, a, b any) Expr {
+	return WhereExpr{col: col, op: "NOT BETWEEN", raw: " NOT BETWEEN ? AND ?", args: []any{a, b}}
 }
 
 func Raw(s string, args ...any) Expr {
@@ -38,48 +44,201 @@ func Raw(s string, args ...any) Expr {
 }
 
 func Null(col string) Expr {
-	return WhereExpr{col: col, raw: " IS NULL"}
+	return WhereExpr{col: col, op: "IS NULL", raw: " IS NULL"}
 }
 
 func NotNull(col string) Expr {
-	return WhereExpr{col: col, raw: " IS NOT NULL"}
+	return WhereExpr{col: col, op: "IS NOT NULL", raw: " IS NOT NULL"}
+}
+
+func IsTrue(col string) Expr {
+	return WhereExpr{col: col, op: "IS TRUE", raw: " IS TRUE"}
+}
+
+func IsFalse(col string) Expr {
+	return WhereExpr{col: col, op: "IS FALSE", raw: " IS FALSE"}
 }
 
 func In(col string, args ...any) Expr {
-	return WhereExpr{executor: func(table string) (string, []any, error) {
-		var raw strings.Builder
-		for _, arg := range args {
-			if reflect.TypeOf(arg).Kind() == reflect.Slice {
-				rv := reflect.ValueOf(arg)
-				args = make([]any, 0)
-				for i := 0; i < rv.Len(); i++ {
-					raw.WriteString(", ?")
-					args = append(args, rv.Index(i).Interface())
-				}
-				break
+	return in(col, "IN", args)
+}
+
+func NotIn(col string, args ...any) Expr {
+	return in(col, "NOT IN", args)
+}
+
+func InSlice[T any](col string, values []T) Expr {
+	return in(col, "IN", []any{values})
+}
+
+func NotInSlice[T any](col string, values []T) Expr {
+	return in(col, "NOT IN", []any{values})
+}
+
+// InTuple matches composite keys, e.g. (`a`, `b`) IN ((?, ?), (?, ?)).
+func InTuple(cols []string, rows ...[]any) Expr {
+	return inTuple(cols, "IN", rows)
+}
+
+func NotInTuple(cols []string, rows ...[]any) Expr {
+	return inTuple(cols, "NOT IN", rows)
+}
+
+func in(col, op string, args []any) Expr {
+	values := flatten(args)
+	return WhereExpr{col: col, op: op, args: values, executor: func(_ Dialect, table string) (string, []any, error) {
+		if len(values) < 1 {
+			return emptyIn(op), nil, nil
+		}
+		return Quote(table, col) + " " + op + " " + holders(len(values)), values, nil
+	}}
+}
+
+func inTuple(cols []string, op string, rows [][]any) Expr {
+	var values []any
+	for _, row := range rows {
+		values = append(values, row...)
+	}
+
+	return WhereExpr{cols: cols, op: op, args: values, executor: func(_ Dialect, table string) (string, []any, error) {
+		if len(cols) < 1 {
+			return "", nil, errors.New("tuple IN requires at least one column")
+		}
+
+		if len(rows) < 1 {
+			return emptyIn(op), nil, nil
+		}
+
+		var (
+			sb   strings.Builder
+			args = make([]any, 0, len(cols)*len(rows))
+		)
+
+		sb.WriteString("(")
+		for i, col := range cols {
+			if i > 0 {
+				sb.WriteString(", ")
 			}
+			sb.WriteString(Quote(table, col))
 		}
+		sb.WriteString(") ")
+		sb.WriteString(op)
+		sb.WriteString(" (")
 
-		if raw.Len() < 1 {
-			for range args {
-				raw.WriteString(", ?")
+		row := holders(len(cols))
+		for i, values := range rows {
+			if len(values) != len(cols) {
+				return "", nil, fmt.Errorf("tuple IN row %d has %d values, want %d", i, len(values), len(cols))
+			}
+			if i > 0 {
+				sb.WriteString(", ")
 			}
+			sb.WriteString(row)
+			args = append(args, values...)
 		}
+		sb.WriteString(")")
 
-		return Quote(table, col) + " IN (" + raw.String()[2:] + ")", args, nil
+		return sb.String(), args, nil
 	}}
 }
 
+// emptyIn renders an IN over no values: nothing is IN an empty set,
+// everything is NOT IN it.
+func emptyIn(op string) string {
+	if op == "IN" {
+		return "1 = 0"
+	}
+	return "1 = 1"
+}
+
+// flatten expands slice arguments in place. A nil argument stays a bound
+// NULL, so NotIn keeps SQL's meaning of matching nothing next to a NULL.
+func flatten(args []any) []any {
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		if _, ok := arg.([]byte); ok {
+			values = append(values, arg)
+			continue
+		}
+
+		if rv := reflect.ValueOf(arg); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				values = append(values, rv.Index(i).Interface())
+			}
+			continue
+		}
+
+		values = append(values, arg)
+	}
+	return values
+}
+
+func holders(n int) string {
+	return "(" + strings.Repeat(", ?", n)[2:] + ")"
+}
+
+// likeEscaper escapes LIKE wildcards with '!', which needs no extra quoting
+// in the ESCAPE clause whatever the sql_mode is.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 func Like(col string, val string) Expr {
-	return WhereExpr{col: col, op: "LIKE", args: []any{"%" + val + "%"}}
+	return WhereExpr{col: col, op: "LIKE", raw: " LIKE ? ESCAPE '!'", args: []any{"%" + EscapeLike(val) + "%"}}
 }
 
 func RLike(col string, val string) Expr {
-	return WhereExpr{col: col, op: "LIKE", args: []any{val + "%"}}
+	return WhereExpr{col: col, op: "LIKE", raw: " LIKE ? ESCAPE '!'", args: []any{EscapeLike(val) + "%"}}
 }
 
 func LLike(col string, val string) Expr {
-	return WhereExpr{col: col, op: "LIKE", args: []any{"%" + val}}
+	return WhereExpr{col: col, op: "LIKE", raw: " LIKE ? ESCAPE '!'", args: []any{"%" + EscapeLike(val)}}
+}
+
+func NotLike(col string, val string) Expr {
+	return WhereExpr{col: col, op: "NOT LIKE", raw: " NOT LIKE ? ESCAPE '!'", args: []any{"%" + EscapeLike(val) + "%"}}
+}
+
+// LikeRaw matches against the pattern as given, wildcards included.
+func LikeRaw(col string, pattern string) Expr {
+	return WhereExpr{col: col, op: "LIKE", args: []any{pattern}}
+}
+
+// ILike matches case-insensitively regardless of the column collation.
+func ILike(col string, val string) Expr {
+	args := []any{"%" + EscapeLike(val) + "%"}
+	return WhereExpr{col: col, op: "ILIKE", args: args, executor: func(d Dialect, table string) (string, []any, error) {
+		if d == Postgres {
+			return Quote(table, col) + " ILIKE ? ESCAPE '!'", args, nil
+		}
+		return "LOWER(" + Quote(table, col) + ") LIKE LOWER(?) ESCAPE '!'", args, nil
+	}}
+}
+
+// Regexp matches col against a regular expression, with ~ on Postgres. SQLite
+// needs a regexp() function registered with the driver.
+func Regexp(col string, pattern string) Expr {
+	return regexp(col, "REGEXP", "~", pattern)
+}
+
+func NotRegexp(col string, pattern string) Expr {
+	return regexp(col, "NOT REGEXP", "!~", pattern)
+}
+
+func regexp(col, op, pgOp, pattern string) Expr {
+	args := []any{pattern}
+	return WhereExpr{col: col, op: op, args: args, executor: func(d Dialect, table string) (string, []any, error) {
+		if d == Postgres {
+			return Quote(table, col) + " " + pgOp + " ?", args, nil
+		}
+		return Quote(table, col) + " " + op + " ?", args, nil
+	}}
+}
+
+func Not(e Expr) Expr {
+	return notExpr{expr: e}
 }
 
 func And(a ...Expr) Expr {
@@ -90,70 +249,111 @@ func Or(a ...Expr) Expr {
 	return subExpr{typ: "OR", exprs: a}
 }
 
+// Build renders a joined by typ, "AND" or "OR". Nested groups are
+// parenthesised only where precedence requires it: an OR inside an AND, never
+// an AND inside an OR. With sub set, the result is parenthesised as a whole
+// when it joins more than one condition.
 func Build(table, typ string, sub bool, a ...Expr) (string, []any, error) {
-	var (
-		sb      strings.Builder
-		args    []any
-		typStr      = " " + typ + " "
-		opened  int = -1
-		bracket bool
-	)
+	return BuildFor(MySQL, table, typ, sub, a...)
+}
 
-	if sub {
-		for _, e := range a {
-			if e.Sub() {
-				bracket = true
-				break
-			}
-		}
+// BuildFor is Build for dialect d.
+func BuildFor(d Dialect, table, typ string, sub bool, a ...Expr) (string, []any, error) {
+	parts, args, err := buildGroup(d, table, typ, a)
+	if err != nil {
+		return "", nil, err
 	}
 
-	for i, e := range a {
-		if e.Sub() {
-			if bracket && opened > -1 {
-				sb.WriteString(")")
-				opened = -1
-			}
+	conds := make([]string, len(parts))
+	for i, p := range parts {
+		conds[i] = p.cond
+	}
 
-			if i > 0 {
-				sb.WriteString(typStr)
+	sq := strings.Join(conds, " "+typ+" ")
+	if sub && len(parts) > 1 {
+		sq = "(" + sq + ")"
+	}
+
+	return sq, args, nil
+}
+
+// groupPart is one operand of a rendered group. Raw conditions are left bare
+// in an OR, where nothing binds looser, and marked so that they are
+// parenthesised once flattened into an AND.
+type groupPart struct {
+	cond string
+	raw  bool
+}
+
+// buildGroup renders the operands of a typ group, flattening nested groups
+// into it where that keeps the meaning. Empty groups render nothing, as they
+// hold for every row; an OR with such an operand holds for every row too.
+func buildGroup(d Dialect, table, typ string, a []Expr) ([]groupPart, []any, error) {
+	var (
+		parts []groupPart
+		args  []any
+	)
+
+	for _, e := range a {
+		if g, ok := e.(subExpr); ok {
+			sub, subArgs, err := buildGroup(d, table, g.typ, g.exprs)
+			if err != nil {
+				return nil, nil, err
 			}
-		} else {
-			if opened == -1 {
-				if i > 0 {
-					sb.WriteString(typStr)
-				}
 
-				if bracket {
-					sb.WriteString("(")
+			switch {
+			case len(sub) < 1 && typ == "OR":
+				return nil, nil, nil
+			case len(sub) < 1:
+				continue
+			case len(sub) == 1 || g.typ == typ:
+				parts = append(parts, sub...)
+			default:
+				conds := make([]string, len(sub))
+				for i, p := range sub {
+					conds[i] = p.cond
+				}
+				cond := strings.Join(conds, " "+g.typ+" ")
+				if g.typ == "OR" {
+					cond = "(" + cond + ")"
 				}
-			} else if opened > -1 {
-				sb.WriteString(typStr)
+				parts = append(parts, groupPart{cond: cond})
 			}
 
-			if bracket {
-				opened++
-			}
+			args = append(args, subArgs...)
+			continue
 		}
 
-		out, whereArgs, err := e.Build(table)
+		out, exprArgs, err := BuildExpr(d, table, e)
 		if err != nil {
-			return "", nil, err
+			return nil, nil, err
 		}
 
-		args = append(args, whereArgs...)
-		sb.WriteString(out)
-	}
+		if out == "" {
+			if typ == "OR" {
+				return nil, nil, nil
+			}
+			continue
+		}
 
-	if opened > 0 {
-		sb.WriteString(")")
+		parts = append(parts, groupPart{cond: out, raw: isRaw(e)})
+		args = append(args, exprArgs...)
 	}
 
-	sq := sb.String()
-
-	if sub && len(a) > 1 {
-		sq = "(" + sq + ")"
+	// whatever a raw condition holds, AND must not bind into it
+	if typ != "OR" && len(parts) > 1 {
+		for i, p := range parts {
+			if p.raw {
+				parts[i] = groupPart{cond: "(" + p.cond + ")"}
+			}
+		}
 	}
 
-	return sq, args, nil
+	return parts, args, nil
+}
+
+// isRaw reports whether e is a qb.Raw condition.
+func isRaw(e Expr) bool {
+	w, ok := e.(WhereExpr)
+	return ok && w.col == "" && w.executor == nil
 }
@@ -0,0 +1,226 @@
+package qb
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testCols = 4
+
+// row holds a 0 or 1 for every test column.
+type row [testCols]int
+
+// rawOrs are the spellings of OR joining raw test conditions.
+var rawOrs = []string{" OR ", "\nOR ", "\tor\t", " OR\n"}
+
+// randExpr builds a random And/Or/Not tree over equality tests of the test
+// columns, with raw conditions containing OR and empty groups mixed in.
+func randExpr(r *rand.Rand, depth int) Expr {
+	if depth <= 0 || r.Intn(4) == 0 {
+		switch r.Intn(6) {
+		case 0:
+			or := rawOrs[r.Intn(len(rawOrs))]
+			return Raw(fmt.Sprintf("`c%d` = ?"+or+"`c%d` = ?", r.Intn(testCols), r.Intn(testCols)), r.Intn(2), r.Intn(2))
+		default:
+			return Eq(fmt.Sprintf("c%d", r.Intn(testCols)), r.Intn(2))
+		}
+	}
+
+	switch r.Intn(3) {
+	case 0:
+		return Not(randExpr(r, depth-1))
+	default:
+		exprs := make([]Expr, r.Intn(4))
+		for i := range exprs {
+			exprs[i] = randExpr(r, depth-1)
+		}
+		if r.Intn(2) == 0 {
+			return And(exprs...)
+		}
+		return Or(exprs...)
+	}
+}
+
+// eval evaluates e directly. A group holds for every row when empty, and
+// otherwise when all (AND) or any (OR) of its operands hold.
+func eval(e Expr, rw row) bool {
+	switch x := e.(type) {
+	case subExpr:
+		if len(x.exprs) < 1 {
+			return true
+		}
+		for _, child := range x.exprs {
+			if eval(child, rw) == (x.typ == "OR") {
+				return x.typ == "OR"
+			}
+		}
+		return x.typ == "AND"
+
+	case notExpr:
+		return !eval(x.expr, rw)
+
+	case WhereExpr:
+		if x.col != "" {
+			return rw[colIndex(x.col)] == x.args[0].(int)
+		}
+		f := strings.Fields(x.raw)
+		a, b := colIndex(f[0]), colIndex(f[4])
+		return rw[a] == x.args[0].(int) || rw[b] == x.args[1].(int)
+	}
+	panic(fmt.Sprintf("unexpected %T", e))
+}
+
+// colIndex reads the index of a test column, e.g. 2 for c2 or `t`.`c2`.
+func colIndex(col string) int {
+	i, _ := strconv.Atoi(strings.Trim(col[strings.LastIndex(col, "c")+1:], "`"))
+	return i
+}
+
+// sqlEval evaluates rendered SQL with standard precedence: NOT binds tighter
+// than AND, which binds tighter than OR.
+type sqlEval struct {
+	toks []string
+	args []any
+	rw   row
+}
+
+func tokenize(sq string) []string {
+	sq = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(sq)
+	return strings.Fields(sq)
+}
+
+func (p *sqlEval) next() string {
+	tok := p.toks[0]
+	p.toks = p.toks[1:]
+	return tok
+}
+
+func (p *sqlEval) peek() string {
+	if len(p.toks) < 1 {
+		return ""
+	}
+	return p.toks[0]
+}
+
+func (p *sqlEval) or() bool {
+	v := p.and()
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		w := p.and()
+		v = v || w
+	}
+	return v
+}
+
+func (p *sqlEval) and() bool {
+	v := p.not()
+	for p.peek() == "AND" {
+		p.next()
+		w := p.not()
+		v = v && w
+	}
+	return v
+}
+
+func (p *sqlEval) not() bool {
+	if p.peek() == "NOT" {
+		p.next()
+		return !p.not()
+	}
+	if p.peek() == "(" {
+		p.next()
+		v := p.or()
+		if p.next() != ")" {
+			panic("unbalanced parentheses")
+		}
+		return v
+	}
+
+	left := p.value(p.next())
+	if p.next() != "=" {
+		panic("expected =")
+	}
+	return left == p.value(p.next())
+}
+
+func (p *sqlEval) value(tok string) int {
+	switch {
+	case tok == "?":
+		v := p.args[0].(int)
+		p.args = p.args[1:]
+		return v
+	case strings.Contains(tok, "`c"):
+		return p.rw[colIndex(tok)]
+	default:
+		n, _ := strconv.Atoi(tok)
+		return n
+	}
+}
+
+func TestBuildSemantics(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20000; i++ {
+		e := randExpr(r, 4)
+
+		sq, args, err := Build("t", "AND", false, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for mask := 0; mask < 1<<testCols; mask++ {
+			var rw row
+			for c := range rw {
+				rw[c] = mask >> c & 1
+			}
+
+			want := eval(e, rw)
+
+			got := true
+			if sq != "" {
+				p := &sqlEval{toks: tokenize(sq), args: args, rw: rw}
+				got = p.or()
+				if len(p.toks) > 0 || len(p.args) > 0 {
+					t.Fatalf("%s: left over tokens %v or args %v", sq, p.toks, p.args)
+				}
+			}
+
+			if got != want {
+				t.Fatalf("row %v: %s evaluates to %v, want %v", rw, sq, got, want)
+			}
+		}
+	}
+}
+
+func TestBuildParentheses(t *testing.T) {
+	for _, tc := range []struct {
+		expr Expr
+		want string
+	}{
+		{Or(Eq("a", 1), And(Eq("b", 2), Eq("c", 3)), Eq("d", 4)), "(`t`.`a` = ? OR `t`.`b` = ? AND `t`.`c` = ? OR `t`.`d` = ?)"},
+		{And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3))), "`t`.`a` = ? AND (`t`.`b` = ? OR `t`.`c` = ?)"},
+		{And(Eq("a", 1), And(Eq("b", 2))), "`t`.`a` = ? AND `t`.`b` = ?"},
+		{Not(Or(Eq("a", 1), Eq("b", 2))), "NOT (`t`.`a` = ? OR `t`.`b` = ?)"},
+		{And(Eq("a", 1), Raw("x = 1 OR y = 2")), "`t`.`a` = ? AND (x = 1 OR y = 2)"},
+		{And(Eq("a", 1), Or(Raw("x = 1 OR y = 2"))), "`t`.`a` = ? AND (x = 1 OR y = 2)"},
+		{And(Eq("a", 1), Raw("x = 1\nOR y = 2")), "`t`.`a` = ? AND (x = 1\nOR y = 2)"},
+		{And(Eq("a", 1), Raw("x = 1 OR(y = 2)")), "`t`.`a` = ? AND (x = 1 OR(y = 2))"},
+		{And(Raw("x = 1 OR y = 2")), "x = 1 OR y = 2"},
+		{Not(And()), "1 = 0"},
+		{In("a", 1, Col("b")), "`t`.`a` IN (?, `t`.`b`)"},
+		{InTuple([]string{"a", "b"}, []any{1, Col("c")}), "(`t`.`a`, `t`.`b`) IN ((?, `t`.`c`))"},
+		{Or(Eq("a", 1), And()), ""},
+		{And(Eq("a", 1), Or()), "`t`.`a` = ?"},
+	} {
+		got, _, err := Build("t", "AND", false, tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("got %s, want %s", got, tc.want)
+		}
+	}
+}
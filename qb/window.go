@@ -0,0 +1,247 @@
+package qb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WindowSpec is the PARTITION BY / ORDER BY clause of a window, used inline
+// with WindowFunc.Over or named with Builder.Window.
+type WindowSpec struct {
+	partition []string
+	order     []windowOrder
+}
+
+type windowOrder struct {
+	col, dir string
+}
+
+func Window() *WindowSpec {
+	return &WindowSpec{}
+}
+
+func (w *WindowSpec) PartitionBy(cols ...string) *WindowSpec {
+	w.partition = append(w.partition, cols...)
+	return w
+}
+
+func (w *WindowSpec) OrderBy(col string, sortBy ...SortBy) *WindowSpec {
+	o := windowOrder{col: col}
+	if len(sortBy) > 0 {
+		if sortBy[0] == Ascend {
+			o.dir = " ASC"
+		} else {
+			o.dir = " DESC"
+		}
+	}
+	w.order = append(w.order, o)
+	return w
+}
+
+func (w *WindowSpec) Build(table string) string {
+	if w == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	if len(w.partition) > 0 {
+		sb.WriteString("PARTITION BY ")
+		for i, col := range w.partition {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(Quote(table, col))
+		}
+	}
+
+	if len(w.order) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("ORDER BY ")
+		for i, o := range w.order {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(Quote(table, o.col))
+			sb.WriteString(o.dir)
+		}
+	}
+
+	return sb.String()
+}
+
+// WindowFunc is a function evaluated over a window, for Builder.SelectExpr:
+//
+//	qb.RowNumber().Over(qb.Window().PartitionBy("user_id").OrderBy("created_at", qb.Descend))
+//	qb.Sum("amount").OverNamed("w")
+//
+// Sum, Avg, Min, Max and Count without Over or OverNamed are plain
+// aggregates, compared with their methods in Builder.Having:
+//
+//	qb.Sum("amount").Gt(100)
+type WindowFunc struct {
+	fn   string
+	args []any
+
+	over  *WindowSpec
+	named string
+
+	aggregate, windowed bool
+}
+
+func RowNumber() *WindowFunc {
+	return &WindowFunc{fn: "ROW_NUMBER"}
+}
+
+func Rank() *WindowFunc {
+	return &WindowFunc{fn: "RANK"}
+}
+
+func DenseRank() *WindowFunc {
+	return &WindowFunc{fn: "DENSE_RANK"}
+}
+
+// Lag reads col from the row offset rows before the current one, falling
+// back to def when given.
+func Lag(col string, offset int, def ...any) *WindowFunc {
+	return &WindowFunc{fn: "LAG", args: offsetArgs(col, offset, def)}
+}
+
+// Lead reads col from the row offset rows after the current one, falling
+// back to def when given.
+func Lead(col string, offset int, def ...any) *WindowFunc {
+	return &WindowFunc{fn: "LEAD", args: offsetArgs(col, offset, def)}
+}
+
+func Sum(col string) *WindowFunc {
+	return &WindowFunc{fn: "SUM", args: []any{Col(col)}, aggregate: true}
+}
+
+func Avg(col string) *WindowFunc {
+	return &WindowFunc{fn: "AVG", args: []any{Col(col)}, aggregate: true}
+}
+
+func Min(col string) *WindowFunc {
+	return &WindowFunc{fn: "MIN", args: []any{Col(col)}, aggregate: true}
+}
+
+func Max(col string) *WindowFunc {
+	return &WindowFunc{fn: "MAX", args: []any{Col(col)}, aggregate: true}
+}
+
+func Count(col string) *WindowFunc {
+	return &WindowFunc{fn: "COUNT", args: []any{Col(col)}, aggregate: true}
+}
+
+func offsetArgs(col string, offset int, def []any) []any {
+	args := []any{Col(col), rawInt(offset)}
+	if len(def) > 0 {
+		args = append(args, def[0])
+	}
+	return args
+}
+
+// rawInt is inlined since LAG and LEAD only take literal offsets.
+type rawInt int
+
+func (n rawInt) operand(Dialect, string) (string, []any) {
+	return strconv.Itoa(int(n)), nil
+}
+
+func (f *WindowFunc) Over(w *WindowSpec) *WindowFunc {
+	f.over, f.named, f.windowed = w, "", true
+	return f
+}
+
+// OverNamed refers to a window declared with Builder.Window.
+func (f *WindowFunc) OverNamed(name string) *WindowFunc {
+	f.over, f.named, f.windowed = nil, name, true
+	return f
+}
+
+func (*WindowFunc) Sub() bool {
+	return false
+}
+
+func (f *WindowFunc) Build(table string) (string, []any, error) {
+	return f.BuildDialect(MySQL, table)
+}
+
+func (f *WindowFunc) BuildDialect(d Dialect, table string) (string, []any, error) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+
+	sb.WriteString(f.fn)
+	sb.WriteString("(")
+	for i, arg := range f.args {
+		out, argArgs, err := BindFor(d, table, arg)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(out)
+		args = append(args, argArgs...)
+	}
+	sb.WriteString(")")
+
+	if f.aggregate && !f.windowed {
+		return sb.String(), args, nil
+	}
+
+	sb.WriteString(" OVER ")
+
+	if f.named != "" {
+		sb.WriteString(Quote(f.named, ""))
+	} else {
+		sb.WriteString("(")
+		sb.WriteString(f.over.Build(table))
+		sb.WriteString(")")
+	}
+
+	return sb.String(), args, nil
+}
+
+func (f *WindowFunc) compare(op string, val any) Expr {
+	return WhereExpr{op: op, args: []any{val}, executor: func(d Dialect, table string) (string, []any, error) {
+		out, args, err := f.BuildDialect(d, table)
+		if err != nil {
+			return "", nil, err
+		}
+
+		v, valArgs, err := BindFor(d, table, val)
+		if err != nil {
+			return "", nil, err
+		}
+		return out + " " + op + " " + v, append(args, valArgs...), nil
+	}}
+}
+
+func (f *WindowFunc) Eq(val any) Expr {
+	return f.compare("=", val)
+}
+
+func (f *WindowFunc) Neq(val any) Expr {
+	return f.compare("<>", val)
+}
+
+func (f *WindowFunc) Gt(val any) Expr {
+	return f.compare(">", val)
+}
+
+func (f *WindowFunc) Lt(val any) Expr {
+	return f.compare("<", val)
+}
+
+func (f *WindowFunc) Gte(val any) Expr {
+	return f.compare(">=", val)
+}
+
+func (f *WindowFunc) Lte(val any) Expr {
+	return f.compare("<=", val)
+}
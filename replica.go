@@ -0,0 +1,189 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+type Balance int
+
+const (
+	RoundRobin Balance = iota
+	Weighted
+)
+
+type ReplicaOptions struct {
+	Balance Balance
+
+	// Weights holds the weight of each replica by index for Weighted
+	// balancing, missing or non-positive weights count as 1.
+	Weights []int
+
+	// HealthInterval is how often replicas are pinged, 5s when zero. A
+	// replica failing its ping gets no reads until it answers again.
+	HealthInterval time.Duration
+}
+
+// ReplicaSet is an Executor sending Exec to the primary and read queries to a
+// healthy replica, or to the primary when none is. Queries that write or lock,
+// such as INSERT ... RETURNING or SELECT ... FOR UPDATE, go to the primary.
+type ReplicaSet struct {
+	primary  *sql.DB
+	replicas []*replica
+	balance  Balance
+
+	next atomic.Uint64
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type replica struct {
+	db      *sql.DB
+	weight  int
+	healthy atomic.Bool
+}
+
+func NewReplicaSet(primary *sql.DB, replicas []*sql.DB, opts ReplicaOptions) *ReplicaSet {
+	s := &ReplicaSet{primary: primary, balance: opts.Balance, stop: make(chan struct{})}
+
+	for i, db := range replicas {
+		r := &replica{db: db, weight: 1}
+		if i < len(opts.Weights) && opts.Weights[i] > 0 {
+			r.weight = opts.Weights[i]
+		}
+		r.healthy.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+
+	interval := opts.HealthInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	if len(s.replicas) > 0 {
+		go s.check(interval)
+	}
+
+	return s
+}
+
+func (s *ReplicaSet) Exec(query string, args ...any) (sql.Result, error) {
+	return s.primary.Exec(query, args...)
+}
+
+func (s *ReplicaSet) Query(query string, args ...any) (*sql.Rows, error) {
+	return s.route(query).Query(query, args...)
+}
+
+// route picks the database for query, a replica only when it reads.
+func (s *ReplicaSet) route(query string) *sql.DB {
+	if !readOnly(query) {
+		return s.primary
+	}
+	return s.Replica()
+}
+
+// readOnly reports whether query only reads, judged by its keywords; any
+// doubt sends it to the primary.
+func readOnly(query string) bool {
+	words := strings.FieldsFunc(strings.ToUpper(query), func(c rune) bool {
+		return c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	if len(words) < 1 {
+		return false
+	}
+
+	switch words[0] {
+	case "SELECT", "WITH", "SHOW", "EXPLAIN", "DESCRIBE":
+	default:
+		return false
+	}
+
+	for i, w := range words {
+		switch {
+		case w == "INSERT" || w == "UPDATE" || w == "DELETE":
+			// also FOR UPDATE, which locks rows on the primary only
+			return false
+		case w == "SHARE" && i > 0 && (words[i-1] == "FOR" || words[i-1] == "IN"):
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ReplicaSet) Primary() *sql.DB {
+	return s.primary
+}
+
+// Replica picks the replica for the next read.
+func (s *ReplicaSet) Replica() *sql.DB {
+	var (
+		healthy = make([]*replica, 0, len(s.replicas))
+		total   int
+	)
+	for _, r := range s.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+			total += r.weight
+		}
+	}
+
+	if len(healthy) < 1 {
+		return s.primary
+	}
+
+	n := s.next.Add(1) - 1
+
+	if s.balance != Weighted {
+		return healthy[n%uint64(len(healthy))].db
+	}
+
+	pick := int(n % uint64(total))
+	for _, r := range healthy {
+		if pick < r.weight {
+			return r.db
+		}
+		pick -= r.weight
+	}
+	return healthy[0].db
+}
+
+func (s *ReplicaSet) check(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range s.replicas {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			r.healthy.Store(r.db.PingContext(ctx) == nil)
+			cancel()
+		}
+	}
+}
+
+// Close stops the health checks and closes the replicas, but not the
+// primary.
+func (s *ReplicaSet) Close() error {
+	s.once.Do(func() { close(s.stop) })
+
+	var errs []error
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
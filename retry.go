@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RetryPolicy retries work failing with a transient error such as a deadlock.
+// The zero value runs the work once.
+type RetryPolicy struct {
+	// Attempts is the total number of runs, at least one.
+	Attempts int
+
+	// Backoff is the delay before the first retry, doubled for each one
+	// after and capped by MaxBackoff when set.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable decides which errors are retried, IsRetryable when nil.
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) Do(fn func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	var (
+		err   error
+		delay = p.Backoff
+	)
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= p.Attempts || !retryable(err) {
+			return err
+		}
+
+		if delay > 0 {
+			time.Sleep(delay)
+			if delay *= 2; p.MaxBackoff > 0 && delay > p.MaxBackoff {
+				delay = p.MaxBackoff
+			}
+		}
+	}
+}
+
+// IsRetryable reports whether err is a deadlock, lock wait timeout or
+// serialization failure, after which the whole transaction may be retried.
+// Driver errors are recognised without importing the drivers: MySQL error
+// numbers, Postgres SQLSTATEs and SQLite busy messages.
+func IsRetryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if s, ok := err.(interface{ SQLState() string }); ok {
+			if retryableState(s.SQLState()) {
+				return true
+			}
+		}
+
+		rv := reflect.Indirect(reflect.ValueOf(err))
+		if rv.Kind() == reflect.Struct {
+			// github.com/go-sql-driver/mysql.MySQLError
+			if f := rv.FieldByName("Number"); f.IsValid() && f.CanUint() {
+				switch f.Uint() {
+				case 1205, 1213:
+					return true
+				}
+			}
+			// github.com/lib/pq.Error
+			if f := rv.FieldByName("Code"); f.IsValid() && f.Kind() == reflect.String && retryableState(f.String()) {
+				return true
+			}
+		}
+
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "deadlock") || strings.Contains(msg, "database is locked") || strings.Contains(msg, "could not serialize access") {
+			return true
+		}
+	}
+	return false
+}
+
+func retryableState(state string) bool {
+	return state == "40001" || state == "40P01"
+}
@@ -1,7 +1,7 @@
 package orm
 
 import (
-	"log"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -60,53 +60,139 @@ func (b *Builder) join(typ, target, first, second string) *Builder {
 		sb.WriteString(")")
 	}
 
-	b.joins = append(b.joins, sb.String())
+	b.joins = append(b.joins, qb.Raw(sb.String()))
 	return b
 }
 
+// JoinOn joins target on arbitrary conditions; compare columns of both
+// tables with qb.Col, e.g. qb.Eq("users.id", qb.Col("orders.user_id")).
+func (b *Builder) JoinOn(target string, on ...qb.Expr) *Builder {
+	return b.joinOn("INNER", target, on)
+}
+
+func (b *Builder) LeftJoinOn(target string, on ...qb.Expr) *Builder {
+	return b.joinOn("LEFT", target, on)
+}
+
+func (b *Builder) RightJoinOn(target string, on ...qb.Expr) *Builder {
+	return b.joinOn("RIGHT", target, on)
+}
+
+func (b *Builder) joinOn(typ, target string, on []qb.Expr) *Builder {
+	b.joins = append(b.joins, joinExpr{typ: typ, target: target, on: on})
+	return b
+}
+
+type joinExpr struct {
+	typ, target string
+	on          []qb.Expr
+}
+
+func (joinExpr) Sub() bool {
+	return false
+}
+
+func (j joinExpr) Build(table string) (string, []any, error) {
+	return j.BuildDialect(MySQL, table)
+}
+
+func (j joinExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	cond, args, err := qb.BuildFor(d, table, "AND", false, j.on...)
+	if err != nil {
+		return "", nil, err
+	}
+	if cond == "" {
+		cond = "1 = 1"
+	}
+	return " " + j.typ + " JOIN " + qb.Quote(j.target, "") + " ON (" + cond + ")", args, nil
+}
+
 func (b *Builder) reset() *Builder {
 	b.args = []any{}
 
 	b.cols = []string{}
+	b.selects = []qb.Expr{}
 	b.exprs = []qb.Expr{}
 
 	b.group = ""
 	b.having = []qb.Expr{}
 
-	b.order = ""
+	b.windows = []namedWindow{}
+	b.qualify = []qb.Expr{}
+
+	b.order = nil
 
 	b.offset = -1
 	b.limit = -1
 
-	b.joins = []string{}
+	b.joins = []qb.Expr{}
 
 	return b
 }
 
 func (b *Builder) OrderBy(col string, sortBy ...qb.SortBy) *Builder {
 	if col == "" {
-		b.order = ""
+		b.order = nil
 		return b
 	}
 
-	var raw = qb.Quote(b.table, col)
-	if len(sortBy) > 0 {
-		if sortBy[0] == qb.Ascend {
-			raw += " ASC"
-		} else {
-			raw += " DESC"
-		}
+	b.order = orderExpr{col: col, suffix: sortSuffix(sortBy)}
+	return b
+}
+
+func (b *Builder) OrderByRaw(raw string) *Builder {
+	if raw == "" {
+		b.order = nil
+		return b
 	}
 
-	b.order = raw
+	b.order = qb.Raw(raw)
 	return b
 }
 
-func (b *Builder) OrderByRaw(raw string) *Builder {
-	b.order = raw
+// OrderByExpr orders by a computed expression such as a qb.Match score.
+func (b *Builder) OrderByExpr(e qb.Expr, sortBy ...qb.SortBy) *Builder {
+	b.order = orderExpr{expr: e, suffix: sortSuffix(sortBy)}
 	return b
 }
 
+func sortSuffix(sortBy []qb.SortBy) string {
+	if len(sortBy) < 1 {
+		return ""
+	}
+	if sortBy[0] == qb.Ascend {
+		return " ASC"
+	}
+	return " DESC"
+}
+
+// orderExpr orders by col of the table the builder targets, or by expr.
+type orderExpr struct {
+	col    string
+	expr   qb.Expr
+	suffix string
+}
+
+func (orderExpr) Sub() bool {
+	return false
+}
+
+func (o orderExpr) Build(table string) (string, []any, error) {
+	return o.BuildDialect(MySQL, table)
+}
+
+func (o orderExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	if o.col != "" {
+		return qb.Quote(table, o.col) + o.suffix, nil, nil
+	}
+
+	out, args, err := qb.BuildExpr(d, table, o.expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return out + o.suffix, args, nil
+}
+
 func (b *Builder) Select(cols ...string) *Builder {
 	if len(cols) > 0 {
 		b.cols = cols
@@ -114,11 +200,90 @@ func (b *Builder) Select(cols ...string) *Builder {
 	return b
 }
 
+// SelectExpr adds a computed column to the select list, e.g. a qb.Match
+// relevance score.
+func (b *Builder) SelectExpr(e qb.Expr, as string) *Builder {
+	b.selects = append(b.selects, aliasExpr{expr: e, as: as})
+	return b
+}
+
+type aliasExpr struct {
+	expr qb.Expr
+	as   string
+}
+
+func (aliasExpr) Sub() bool {
+	return false
+}
+
+func (a aliasExpr) Build(table string) (string, []any, error) {
+	return a.BuildDialect(MySQL, table)
+}
+
+func (a aliasExpr) BuildDialect(d Dialect, table string) (string, []any, error) {
+	out, args, err := qb.BuildExpr(d, table, a.expr)
+	if err != nil {
+		return "", nil, err
+	}
+	if a.as == "" {
+		return out, args, nil
+	}
+	return out + " AS " + qb.Quote(a.as, ""), args, nil
+}
+
 func (b *Builder) Where(a ...qb.Expr) *Builder {
 	b.exprs = append(b.exprs, a...)
 	return b
 }
 
+// Conditions returns the WHERE expressions added so far, for inspection with
+// qb.Walk or qb.Columns.
+func (b *Builder) Conditions() []qb.Expr {
+	return b.exprs
+}
+
+// RequireConditions returns the error Update and Delete return when the
+// conditions of b render to nothing, e.g. "updating" rows, for callers adding
+// filters of their own such as a soft delete column before building.
+func (b *Builder) RequireConditions(op string) error {
+	cond, _, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.exprs...)
+	if err != nil {
+		return err
+	}
+	if cond == "" {
+		return fmt.Errorf("not allow %s rows with no where conditions", op)
+	}
+	return nil
+}
+
+// Rewrite replaces the WHERE, HAVING, QUALIFY and JoinOn conditions through
+// qb.Rewrite, e.g. to enforce or strip a tenant filter before the query is
+// built. Joins added with Join, LeftJoin and the like have no expressions to
+// rewrite.
+func (b *Builder) Rewrite(fn func(e qb.Expr) qb.Expr) *Builder {
+	b.exprs = rewriteAll(b.exprs, fn)
+	b.having = rewriteAll(b.having, fn)
+	b.qualify = rewriteAll(b.qualify, fn)
+
+	for i, join := range b.joins {
+		if j, ok := join.(joinExpr); ok {
+			j.on = rewriteAll(j.on, fn)
+			b.joins[i] = j
+		}
+	}
+	return b
+}
+
+func rewriteAll(a []qb.Expr, fn func(e qb.Expr) qb.Expr) []qb.Expr {
+	out := make([]qb.Expr, 0, len(a))
+	for _, e := range a {
+		if e = qb.Rewrite(e, fn); e != nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func (b *Builder) GroupBy(cols ...string) *Builder {
 	var group string
 	for i, col := range cols {
@@ -136,6 +301,25 @@ func (b *Builder) Having(a ...qb.Expr) *Builder {
 	return b
 }
 
+// Window declares a named window for qb.WindowFunc.OverNamed.
+func (b *Builder) Window(name string, spec *qb.WindowSpec) *Builder {
+	b.windows = append(b.windows, namedWindow{name: name, spec: spec})
+	return b
+}
+
+type namedWindow struct {
+	name string
+	spec *qb.WindowSpec
+}
+
+// Qualify filters on select aliases such as window function results. The
+// query is wrapped as a subquery aliased to the table name, and ordering and
+// limits apply to the filtered rows.
+func (b *Builder) Qualify(a ...qb.Expr) *Builder {
+	b.qualify = append(b.qualify, a...)
+	return b
+}
+
 func (b *Builder) Offset(a int) *Builder {
 	b.offset = a
 	return b
@@ -147,7 +331,24 @@ func (b *Builder) Limit(a int) *Builder {
 }
 
 func (b *Builder) ToSQL() (string, []any, error) {
-	cond, whereArgs, err := qb.Build(b.table, "AND", false, b.exprs...)
+	sq, args, err := b.selectSQL()
+	if err != nil {
+		return "", nil, err
+	}
+
+	b.logger.Printf("[SQL] %s\n", sq)
+	b.logger.Printf("[SQL] %+v\n", args)
+
+	b.reset()
+
+	return sq, args, nil
+}
+
+// selectSQL renders the SELECT without logging it or resetting b.
+func (b *Builder) selectSQL() (string, []any, error) {
+	args := append([]any{}, b.args...)
+
+	cond, whereArgs, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.exprs...)
 	if err != nil {
 		return "", nil, err
 	}
@@ -166,28 +367,42 @@ func (b *Builder) ToSQL() (string, []any, error) {
 		sb.WriteString("`")
 	}
 
+	for _, sel := range b.selects {
+		out, selArgs, err := qb.BuildExpr(b.dialect, b.table, sel)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sb.WriteString(", ")
+		sb.WriteString(out)
+		args = append(args, selArgs...)
+	}
+
 	sb.WriteString(" FROM ")
 	sb.WriteString(qb.Quote(b.table, ""))
 
 	for _, join := range b.joins {
-		sb.WriteString(join)
+		out, joinArgs, err := qb.BuildExpr(b.dialect, b.table, join)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sb.WriteString(out)
 		sb.WriteString(" ")
+		args = append(args, joinArgs...)
 	}
 
 	if cond != "" {
-		cond = strings.TrimPrefix(strings.TrimPrefix(cond, " AND "), " OR ")
-		if cond != "" {
-			sb.WriteString(" WHERE ")
-			sb.WriteString(cond)
-			b.args = append(b.args, whereArgs...)
-		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(cond)
+		args = append(args, whereArgs...)
 	}
 
 	if b.group != "" {
 		sb.WriteString(" GROUP BY ")
 		sb.WriteString(b.group)
 
-		having, havArgs, err := qb.Build(b.table, "AND", false, b.having...)
+		having, havArgs, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.having...)
 		if err != nil {
 			return "", nil, err
 		}
@@ -195,32 +410,65 @@ func (b *Builder) ToSQL() (string, []any, error) {
 		if having != "" {
 			sb.WriteString(" HAVING ")
 			sb.WriteString(having)
-			b.args = append(b.args, havArgs...)
+			args = append(args, havArgs...)
 		}
 	}
 
-	if b.order != "" {
+	for i, w := range b.windows {
+		if i == 0 {
+			sb.WriteString(" WINDOW ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(qb.Quote(w.name, ""))
+		sb.WriteString(" AS (")
+		sb.WriteString(w.spec.Build(b.table))
+		sb.WriteString(")")
+	}
+
+	if len(b.qualify) > 0 {
+		qualify, qualArgs, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.qualify...)
+		if err != nil {
+			return "", nil, err
+		}
+
+		inner := sb.String()
+		sb.Reset()
+		sb.WriteString("SELECT * FROM (")
+		sb.WriteString(inner)
+		sb.WriteString(") AS ")
+		sb.WriteString(qb.Quote(b.table, ""))
+		sb.WriteString(" WHERE ")
+		sb.WriteString(qualify)
+		args = append(args, qualArgs...)
+	}
+
+	if b.order != nil {
+		order, orderArgs, err := qb.BuildExpr(b.dialect, b.table, b.order)
+		if err != nil {
+			return "", nil, err
+		}
+
 		sb.WriteString(" ORDER BY ")
-		sb.WriteString(b.order)
+		sb.WriteString(order)
+		args = append(args, orderArgs...)
 	}
 
 	if b.limit > -1 {
 		sb.WriteString(" LIMIT ")
 
-		if b.offset > -1 {
+		if b.offset > -1 && b.dialect == MySQL {
 			sb.WriteString(strconv.Itoa(b.offset))
 			sb.WriteString(", ")
 		}
 
 		sb.WriteString(strconv.Itoa(b.limit))
-	}
-
-	sq, args := sb.String(), b.args
 
-	log.Printf("[SQL] %s\n", sq)
-	log.Printf("[SQL] %+v\n", args)
-
-	b.reset()
+		if b.offset > -1 && b.dialect != MySQL {
+			sb.WriteString(" OFFSET ")
+			sb.WriteString(strconv.Itoa(b.offset))
+		}
+	}
 
-	return sq, args, nil
+	return qb.Rebind(b.dialect, sb.String()), args, nil
 }
@@ -0,0 +1,167 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+
+	"github.com/maxshaw/orm/qb"
+)
+
+// Shard is where the rows of one shard live.
+type Shard struct {
+	Executor Executor
+	Table    string
+}
+
+// ShardResolver maps shard keys of a table to shards. Generated models with
+// a shard key route through it; queries whose conditions do not pin the key
+// run on every shard returned by All.
+type ShardResolver interface {
+	Resolve(table string, key any) (Shard, error)
+	All(table string) ([]Shard, error)
+}
+
+// ErrShardedTx is returned for sharded models used in a transaction, which is
+// bound to a single database.
+var ErrShardedTx = errors.New("sharded model used in a transaction")
+
+// BindShards returns r with the executors of its shards passed through wrap,
+// e.g. to apply the interceptors, dialect and logger of a client.
+func BindShards(r ShardResolver, wrap func(Executor) Executor) ShardResolver {
+	return boundShards{r: r, wrap: wrap}
+}
+
+type boundShards struct {
+	r    ShardResolver
+	wrap func(Executor) Executor
+}
+
+func (b boundShards) Resolve(table string, key any) (Shard, error) {
+	shard, err := b.r.Resolve(table, key)
+	if err != nil {
+		return Shard{}, err
+	}
+	shard.Executor = b.wrap(shard.Executor)
+	return shard, nil
+}
+
+func (b boundShards) All(table string) ([]Shard, error) {
+	shards, err := b.r.All(table)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := make([]Shard, len(shards))
+	for i, shard := range shards {
+		bound[i] = Shard{Executor: b.wrap(shard.Executor), Table: shard.Table}
+	}
+	return bound, nil
+}
+
+// FailShards returns a ShardResolver failing every lookup with err.
+func FailShards(err error) ShardResolver {
+	return failShards{err: err}
+}
+
+type failShards struct {
+	err error
+}
+
+func (f failShards) Resolve(string, any) (Shard, error) { return Shard{}, f.err }
+
+func (f failShards) All(string) ([]Shard, error) { return nil, f.err }
+
+// ModuloShards spreads keys over Executors by their value modulo the number
+// of executors, hashing non-integer keys. With Suffix set, tables are named
+// after their shard, e.g. orders_0, orders_1.
+type ModuloShards struct {
+	Executors []Executor
+	Suffix    bool
+}
+
+func (m ModuloShards) Resolve(table string, key any) (Shard, error) {
+	if len(m.Executors) < 1 {
+		return Shard{}, errors.New("no shards configured")
+	}
+
+	n, err := shardHash(key)
+	if err != nil {
+		return Shard{}, err
+	}
+	return m.shard(table, int(n%uint64(len(m.Executors)))), nil
+}
+
+func (m ModuloShards) All(table string) ([]Shard, error) {
+	shards := make([]Shard, len(m.Executors))
+	for i := range m.Executors {
+		shards[i] = m.shard(table, i)
+	}
+	return shards, nil
+}
+
+func (m ModuloShards) shard(table string, i int) Shard {
+	if m.Suffix {
+		table += "_" + strconv.Itoa(i)
+	}
+	return Shard{Executor: m.Executors[i], Table: table}
+}
+
+func shardHash(key any) (uint64, error) {
+	rv := reflect.Indirect(reflect.ValueOf(key))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		if n < 0 {
+			n = -n
+		}
+		return uint64(n), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+
+	case reflect.String:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(rv.String()))
+		return h.Sum64(), nil
+
+	default:
+		return 0, fmt.Errorf("unsupported shard key type %T", key)
+	}
+}
+
+// ShardKey finds the value a top level equality condition on col pins, as
+// added by Where(qb.Eq(col, v)).
+func ShardKey(exprs []qb.Expr, col string) (any, bool) {
+	for _, e := range exprs {
+		if c, ok := e.(qb.Cond); ok && c.Column() == col && c.Operator() == "=" && len(c.Args()) == 1 {
+			if _, isCol := c.Args()[0].(qb.Column); !isCol {
+				return c.Args()[0], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Target returns a copy of the builder for another executor and table, e.g.
+// one shard.
+func (b *Builder) Target(executor Executor, table string) *Builder {
+	c := *b
+	c.executor, c.table = executor, table
+	if _, ok := executor.(*bound); ok {
+		c.dialect, c.logger = DialectOf(executor), LoggerOf(executor)
+	}
+
+	c.args = append([]any{}, b.args...)
+	c.cols = append([]string{}, b.cols...)
+	c.selects = append([]qb.Expr{}, b.selects...)
+	c.exprs = append([]qb.Expr{}, b.exprs...)
+	c.having = append([]qb.Expr{}, b.having...)
+	c.windows = append([]namedWindow{}, b.windows...)
+	c.qualify = append([]qb.Expr{}, b.qualify...)
+	c.joins = append([]qb.Expr{}, b.joins...)
+
+	return &c
+}
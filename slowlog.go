@@ -0,0 +1,371 @@
+package orm
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// SlowQuery describes a statement that ran for at least the threshold of a
+// SlowQueryLog.
+type SlowQuery struct {
+	Fingerprint string
+	Query       string
+	Args        []any
+	Elapsed     time.Duration
+
+	// Caller is the file:line that issued the statement, outside this module
+	// and the generated package.
+	Caller string
+
+	// Rows is the number of rows an Exec affected or a query returned, -1
+	// when unknown. database/sql only knows how many rows a query returns
+	// once they are read, so slow queries reach OnSlow when the code reading
+	// them calls ReportRows, as the generated queries do.
+	Rows int64
+
+	Err error
+}
+
+// QueryStats aggregates every execution of one fingerprint.
+type QueryStats struct {
+	Fingerprint string        `json:"fingerprint"`
+	Count       int64         `json:"count"`
+	Slow        int64         `json:"slow"`
+	Errors      int64         `json:"errors"`
+	Total       time.Duration `json:"total"`
+	Max         time.Duration `json:"max"`
+	LastCaller  string        `json:"last_caller"`
+}
+
+// SlowQueryLog times every statement passing through its Intercept, reports
+// those slower than Threshold to OnSlow and keeps per fingerprint statistics
+// for the TopN statements by total time. It serves them as JSON over HTTP.
+type SlowQueryLog struct {
+	Threshold time.Duration
+	TopN      int
+	OnSlow    func(SlowQuery)
+
+	// Dialect of the statements, telling fingerprints how double quotes
+	// read; MySQL when zero.
+	Dialect Dialect
+
+	mu      sync.Mutex
+	stats   map[string]*QueryStats
+	pending map[*sql.Rows]SlowQuery
+}
+
+// statsLimit bounds the distinct fingerprints kept beyond TopN, so that
+// ad-hoc statements cannot grow the log forever. It also bounds the slow
+// queries waiting for ReportRows; beyond it one is reported with Rows -1.
+const statsLimit = 1000
+
+// rowsLogs maps the rows of slow queries to the log waiting for their count.
+var rowsLogs sync.Map
+
+// ReportRows tells the SlowQueryLog that ran the query of rows, if any, that
+// n rows were read from it. Code reading rows from an intercepted executor
+// calls it once done.
+func ReportRows(rows *sql.Rows, n int64) {
+	if l, ok := rowsLogs.LoadAndDelete(rows); ok {
+		l.(*SlowQueryLog).report(rows, n)
+	}
+}
+
+func NewSlowQueryLog(threshold time.Duration, topN int, onSlow func(SlowQuery)) *SlowQueryLog {
+	return &SlowQueryLog{Threshold: threshold, TopN: topN, OnSlow: onSlow}
+}
+
+// Intercept is an Interceptor, e.g. client.Use(log.Intercept).
+func (l *SlowQueryLog) Intercept(next Executor) Executor {
+	return &slowLogExecutor{next: next, log: l}
+}
+
+// Top returns the statistics of the TopN fingerprints by total time.
+func (l *SlowQueryLog) Top() []QueryStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	top := make([]QueryStats, 0, len(l.stats))
+	for _, s := range l.stats {
+		top = append(top, *s)
+	}
+
+	sort.Slice(top, func(i, j int) bool { return top[i].Total > top[j].Total })
+
+	if l.TopN > 0 && len(top) > l.TopN {
+		top = top[:l.TopN]
+	}
+	return top
+}
+
+// Reset drops all statistics.
+func (l *SlowQueryLog) Reset() {
+	l.mu.Lock()
+	l.stats = nil
+	l.mu.Unlock()
+}
+
+func (l *SlowQueryLog) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(l.Top())
+}
+
+// record updates the statistics of query and returns it as a SlowQuery when
+// it is slow and OnSlow is set.
+func (l *SlowQueryLog) record(query string, args []any, elapsed time.Duration, rows int64, err error) (SlowQuery, bool) {
+	var (
+		fp     = FingerprintFor(l.Dialect, query)
+		slow   = elapsed >= l.Threshold
+		caller = callerOutside()
+	)
+
+	l.mu.Lock()
+	if l.stats == nil {
+		l.stats = make(map[string]*QueryStats)
+	}
+
+	s, ok := l.stats[fp]
+	if !ok {
+		if len(l.stats) >= statsLimit {
+			l.evict()
+		}
+		s = &QueryStats{Fingerprint: fp}
+		l.stats[fp] = s
+	}
+
+	s.Count++
+	s.Total += elapsed
+	s.LastCaller = caller
+	if elapsed > s.Max {
+		s.Max = elapsed
+	}
+	if slow {
+		s.Slow++
+	}
+	if err != nil {
+		s.Errors++
+	}
+	l.mu.Unlock()
+
+	if !slow || l.OnSlow == nil {
+		return SlowQuery{}, false
+	}
+	return SlowQuery{Fingerprint: fp, Query: query, Args: args, Elapsed: elapsed, Caller: caller, Rows: rows, Err: err}, true
+}
+
+// wait holds q until the number of rows is reported.
+func (l *SlowQueryLog) wait(rows *sql.Rows, q SlowQuery) {
+	var (
+		flushed SlowQuery
+		flush   bool
+	)
+
+	l.mu.Lock()
+	if l.pending == nil {
+		l.pending = make(map[*sql.Rows]SlowQuery)
+	}
+	if len(l.pending) >= statsLimit {
+		for r, p := range l.pending {
+			rowsLogs.Delete(r)
+			delete(l.pending, r)
+			flushed, flush = p, true
+			break
+		}
+	}
+	l.pending[rows] = q
+	rowsLogs.Store(rows, l)
+	l.mu.Unlock()
+
+	if flush {
+		l.OnSlow(flushed)
+	}
+}
+
+func (l *SlowQueryLog) report(rows *sql.Rows, n int64) {
+	l.mu.Lock()
+	q, ok := l.pending[rows]
+	delete(l.pending, rows)
+	l.mu.Unlock()
+
+	if ok {
+		q.Rows = n
+		l.OnSlow(q)
+	}
+}
+
+// evict drops the fingerprint with the least total time.
+func (l *SlowQueryLog) evict() {
+	var (
+		min   string
+		found bool
+	)
+	for fp, s := range l.stats {
+		if !found || s.Total < l.stats[min].Total {
+			min, found = fp, true
+		}
+	}
+	delete(l.stats, min)
+}
+
+type slowLogExecutor struct {
+	next Executor
+	log  *SlowQueryLog
+}
+
+func (e *slowLogExecutor) Exec(query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := e.next.Exec(query, args...)
+	elapsed := time.Since(start)
+
+	var rows int64 = -1
+	if err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			rows = n
+		}
+	}
+
+	if q, ok := e.log.record(query, args, elapsed, rows, err); ok {
+		e.log.OnSlow(q)
+	}
+	return res, err
+}
+
+func (e *slowLogExecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := e.next.Query(query, args...)
+
+	if q, ok := e.log.record(query, args, time.Since(start), -1, err); ok {
+		if err != nil {
+			e.log.OnSlow(q)
+		} else {
+			e.log.wait(rows, q)
+		}
+	}
+	return rows, err
+}
+
+// Fingerprint normalises query so that statements differing only by literal
+// values, placeholder counts or whitespace compare equal.
+func Fingerprint(query string) string {
+	return FingerprintFor(MySQL, query)
+}
+
+// FingerprintFor is Fingerprint for dialect d. Double quotes delimit strings
+// on MySQL and identifiers, which are kept, elsewhere.
+func FingerprintFor(d Dialect, query string) string {
+	var (
+		sb   strings.Builder
+		prev rune
+	)
+
+	write := func(c rune) {
+		if c == ' ' && (prev == ' ' || prev == 0) {
+			return
+		}
+		sb.WriteRune(c)
+		prev = c
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == '"' && d != MySQL:
+			// a quoted identifier, kept as is
+			write(c)
+			for i++; i < len(runes); i++ {
+				write(runes[i])
+				if runes[i] == '"' {
+					if i+1 < len(runes) && runes[i+1] == '"' {
+						i++
+						write('"')
+						continue
+					}
+					break
+				}
+			}
+
+		case c == '\'' || c == '"':
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && d == MySQL {
+					i++
+					continue
+				}
+				if runes[i] == c {
+					// a doubled quote escapes itself
+					if i+1 < len(runes) && runes[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			write('?')
+
+		case unicode.IsDigit(c) && !isIdentRune(prev):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			write('?')
+
+		case c == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+				i++
+			}
+			write('?')
+
+		case unicode.IsSpace(c):
+			write(' ')
+
+		default:
+			write(c)
+		}
+	}
+
+	fp := strings.TrimSpace(sb.String())
+
+	// collapse lists of placeholders and of value rows
+	for _, r := range [][2]string{{"?, ?", "?"}, {"(?), (?)", "(?)"}} {
+		for strings.Contains(fp, r[0]) {
+			fp = strings.ReplaceAll(fp, r[0], r[1])
+		}
+	}
+
+	return fp
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '`' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// callerOutside returns the file:line of the first frame outside this module
+// and the generated package.
+func callerOutside() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+
+		fn := frame.Function
+		if !strings.HasPrefix(fn, "github.com/maxshaw/orm") &&
+			!strings.HasPrefix(fn, "database/sql") &&
+			!strings.Contains(fn, "/internal/gen.") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
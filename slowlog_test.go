@@ -0,0 +1,41 @@
+package orm
+
+import "testing"
+
+func TestFingerprintFor(t *testing.T) {
+	for _, tc := range []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{MySQL, "SELECT * FROM `users` WHERE `users`.`id` = ?", "SELECT * FROM `users` WHERE `users`.`id` = ?"},
+		{MySQL, "SELECT * FROM `users` WHERE `name` IN (?, ?, ?)", "SELECT * FROM `users` WHERE `name` IN (?)"},
+		{MySQL, `SELECT * FROM users WHERE name = 'it\'s' AND note = "x" AND id = 42`, "SELECT * FROM users WHERE name = ? AND note = ? AND id = ?"},
+		{MySQL, "SELECT * FROM users WHERE name = 'it''s'", "SELECT * FROM users WHERE name = ?"},
+		{MySQL, "INSERT INTO `t` (`a`, `b`) VALUES (1, 'x'), (2, 'y')", "INSERT INTO `t` (`a`, `b`) VALUES (?)"},
+
+		{Postgres, `SELECT "users"."id" FROM "users" WHERE "users"."id" = $1`, `SELECT "users"."id" FROM "users" WHERE "users"."id" = ?`},
+		{Postgres, `SELECT "orders"."id" FROM "orders" WHERE "orders"."id" = $12`, `SELECT "orders"."id" FROM "orders" WHERE "orders"."id" = ?`},
+		{Postgres, `SELECT * FROM "a""b" WHERE name = 'it''s' AND x = 'c:\'`, `SELECT * FROM "a""b" WHERE name = ? AND x = ?`},
+		{Postgres, "SELECT *\n  FROM  \"users\"\tWHERE id IN ($1, $2)", `SELECT * FROM "users" WHERE id IN (?)`},
+
+		{SQLite, `SELECT * FROM "users" WHERE "name" = 'bob'`, `SELECT * FROM "users" WHERE "name" = ?`},
+		{SQLite, "SELECT * FROM `users_2026_10` WHERE `id` = 7", "SELECT * FROM `users_2026_10` WHERE `id` = ?"},
+	} {
+		if got := FingerprintFor(tc.dialect, tc.query); got != tc.want {
+			t.Errorf("%s %q: got %q, want %q", tc.dialect, tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestFromTable(t *testing.T) {
+	for query, want := range map[string]string{
+		"SELECT * FROM `users` WHERE `users`.`id` = ?":    "users",
+		`SELECT "users"."id" FROM "users" WHERE "id" = ?`: "users",
+		"SELECT 1": "",
+	} {
+		if got := fromTable(query); got != want {
+			t.Errorf("%q: got %q, want %q", query, got, want)
+		}
+	}
+}
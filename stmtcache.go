@@ -0,0 +1,215 @@
+package orm
+
+import (
+	"container/list"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+type StmtCacheStats struct {
+	Hits, Misses, Evictions int64
+}
+
+// StmtCache is an Interceptor preparing statements once and reusing them,
+// keeping at most Size per executor, unbounded when zero, and closing the
+// least recently used beyond that. It must be the innermost interceptor,
+// directly wrapping the *sql.DB, *sql.Tx or *ReplicaSet, whose primary and
+// replicas each keep their own statements. Statements of a database are
+// shared by every executor chain built on it until Close; every transaction
+// gets its own cache, released with it. Statistics cover all executors the
+// cache wraps.
+type StmtCache struct {
+	Size int
+
+	hits, misses, evictions atomic.Int64
+
+	mu    sync.Mutex
+	execs map[preparer]*stmtExecutor
+}
+
+func NewStmtCache(size int) *StmtCache {
+	return &StmtCache{Size: size}
+}
+
+func (c *StmtCache) Stats() StmtCacheStats {
+	return StmtCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Evictions: c.evictions.Load()}
+}
+
+// Close closes the statements cached for databases; those in use are closed
+// once their calls return. The cache stays usable and prepares again.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, e := range c.execs {
+		errs = append(errs, e.close())
+	}
+	return errors.Join(errs...)
+}
+
+type preparer interface {
+	Executor
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// Intercept is an Interceptor; executors that cannot prepare statements are
+// returned unchanged.
+func (c *StmtCache) Intercept(next Executor) Executor {
+	if s, ok := next.(*ReplicaSet); ok {
+		return replicaStmts{cache: c, set: s}
+	}
+
+	p, ok := next.(preparer)
+	if !ok {
+		return next
+	}
+
+	// statements prepared on a transaction are closed by database/sql when
+	// it ends, so they are not kept beyond the executor
+	if _, ok := p.(*sql.Tx); ok {
+		return newStmtExecutor(c, p)
+	}
+	return c.shared(p)
+}
+
+// shared returns the executor caching the statements of database p.
+func (c *StmtCache) shared(p preparer) *stmtExecutor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.execs[p]; ok {
+		return e
+	}
+	if c.execs == nil {
+		c.execs = make(map[preparer]*stmtExecutor)
+	}
+	e := newStmtExecutor(c, p)
+	c.execs[p] = e
+	return e
+}
+
+// replicaStmts routes every query like its ReplicaSet, to the statements
+// cached for the database chosen.
+type replicaStmts struct {
+	cache *StmtCache
+	set   *ReplicaSet
+}
+
+func (r replicaStmts) Exec(query string, args ...any) (sql.Result, error) {
+	return r.cache.shared(r.set.primary).Exec(query, args...)
+}
+
+func (r replicaStmts) Query(query string, args ...any) (*sql.Rows, error) {
+	return r.cache.shared(r.set.route(query)).Query(query, args...)
+}
+
+// cachedStmt counts the calls using stmt, so that an evicted statement is
+// closed only once they return.
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+type stmtExecutor struct {
+	cache *StmtCache
+	next  preparer
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+func newStmtExecutor(c *StmtCache, next preparer) *stmtExecutor {
+	return &stmtExecutor{cache: c, next: next, lru: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (e *stmtExecutor) Exec(query string, args ...any) (sql.Result, error) {
+	cached, err := e.acquire(query)
+	if err != nil {
+		return nil, err
+	}
+	defer e.release(cached)
+	return cached.stmt.Exec(args...)
+}
+
+// Query releases the statement once it returns: database/sql defers closing
+// a statement until the rows read from it are closed.
+func (e *stmtExecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	cached, err := e.acquire(query)
+	if err != nil {
+		return nil, err
+	}
+	defer e.release(cached)
+	return cached.stmt.Query(args...)
+}
+
+func (e *stmtExecutor) acquire(query string) (*cachedStmt, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if el, ok := e.items[query]; ok {
+		e.cache.hits.Add(1)
+		e.lru.MoveToFront(el)
+		cached := el.Value.(*cachedStmt)
+		cached.refs++
+		return cached, nil
+	}
+
+	e.cache.misses.Add(1)
+
+	stmt, err := e.next.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	e.items[query] = e.lru.PushFront(cached)
+
+	if e.cache.Size > 0 {
+		for e.lru.Len() > e.cache.Size {
+			e.evict(e.lru.Back())
+			e.cache.evictions.Add(1)
+		}
+	}
+
+	return cached, nil
+}
+
+func (e *stmtExecutor) release(cached *cachedStmt) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cached.refs--
+	if cached.evicted && cached.refs == 0 {
+		_ = cached.stmt.Close()
+	}
+}
+
+// evict drops el from the cache, closing its statement unless in use; e.mu
+// must be held.
+func (e *stmtExecutor) evict(el *list.Element) error {
+	cached := e.lru.Remove(el).(*cachedStmt)
+	delete(e.items, cached.query)
+
+	cached.evicted = true
+	if cached.refs == 0 {
+		return cached.stmt.Close()
+	}
+	return nil
+}
+
+func (e *stmtExecutor) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for e.lru.Len() > 0 {
+		errs = append(errs, e.evict(e.lru.Back()))
+	}
+	return errors.Join(errs...)
+}
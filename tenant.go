@@ -0,0 +1,55 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNoTenant is returned when a tenant scoped model is used by a client
+// that has no tenant.
+var ErrNoTenant = errors.New("tenant scoped model used without a tenant")
+
+// ErrTenantMismatch is returned for writes setting the tenant column of a
+// tenant scoped model to another tenant.
+var ErrTenantMismatch = errors.New("tenant column set to another tenant")
+
+// TenantAs converts the tenant id to T, the type of a tenant column, e.g. an
+// int given to ForTenant for an int64 column. Conversions changing the value
+// fail, as does a nil id with ErrNoTenant.
+func TenantAs[T any](id any) (T, error) {
+	var v T
+	if id == nil {
+		return v, ErrNoTenant
+	}
+	if t, ok := id.(T); ok {
+		return t, nil
+	}
+
+	rv, typ := reflect.ValueOf(id), reflect.TypeOf(v)
+	if numeric(rv.Kind()) && numeric(typ.Kind()) || rv.Kind() == reflect.String && typ.Kind() == reflect.String {
+		conv := rv.Convert(typ)
+		negative := rv.CanInt() && rv.Int() < 0 || rv.CanFloat() && rv.Float() < 0
+		if conv.Convert(rv.Type()).Equal(rv) && !(negative && conv.CanUint()) {
+			return conv.Interface().(T), nil
+		}
+	}
+	return v, fmt.Errorf("tenant %v cannot be used as a %s", id, typ)
+}
+
+func numeric(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+type tenantKey struct{}
+
+// NewTenantContext returns a copy of ctx carrying the tenant id.
+func NewTenantContext(ctx context.Context, id any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantFromContext returns the tenant id carried by ctx, nil when none.
+func TenantFromContext(ctx context.Context) any {
+	return ctx.Value(tenantKey{})
+}
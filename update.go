@@ -2,7 +2,6 @@ package orm
 
 import (
 	"errors"
-	"log"
 	"strconv"
 	"strings"
 
@@ -14,7 +13,7 @@ func (b *Builder) UpdateOne(values qb.H) (string, []any, error) {
 }
 
 func (b *Builder) Update(values qb.H) (string, []any, error) {
-	cond, whereArgs, err := qb.Build(b.table, "AND", false, b.exprs...)
+	cond, whereArgs, err := qb.BuildFor(b.dialect, b.table, "AND", false, b.exprs...)
 	if err != nil {
 		return "", nil, err
 	}
@@ -34,29 +33,52 @@ func (b *Builder) Update(values qb.H) (string, []any, error) {
 		if i > 0 {
 			sb.WriteString(",")
 		}
+		val, valArgs, err := qb.BindFor(b.dialect, b.table, v)
+		if err != nil {
+			return "", nil, err
+		}
+
 		sb.WriteString(" ")
-		sb.WriteString(qb.Quote(b.table, k))
-		sb.WriteString(" = ?")
+		if b.dialect == MySQL {
+			sb.WriteString(qb.Quote(b.table, k))
+		} else {
+			// only MySQL accepts qualified columns in SET
+			sb.WriteString(qb.Quote(k, ""))
+		}
+		sb.WriteString(" = ")
+		sb.WriteString(val)
 
-		b.args = append(b.args, v)
+		b.args = append(b.args, valArgs...)
 		i++
 	}
 
-	if cond != "" {
-		sb.WriteString(" WHERE ")
-		sb.WriteString(cond)
-		b.args = append(b.args, whereArgs...)
-	}
+	sb.WriteString(" WHERE ")
+	sb.WriteString(b.limitWhere(cond))
+	b.args = append(b.args, whereArgs...)
 
-	if b.limit > 0 {
+	if b.limit > 0 && b.dialect == MySQL {
 		sb.WriteString(" LIMIT ")
 		sb.WriteString(strconv.Itoa(b.limit))
 	}
 
-	sq := sb.String()
+	sq := qb.Rebind(b.dialect, sb.String())
 
-	log.Printf("[SQL] %s\n", sq)
-	log.Printf("[SQL] %+v\n", b.args)
+	b.logger.Printf("[SQL] %s\n", sq)
+	b.logger.Printf("[SQL] %+v\n", b.args)
 
 	return sq, b.args, nil
 }
+
+// limitWhere returns the WHERE condition of an UPDATE or DELETE. Only MySQL
+// takes LIMIT there, other dialects select the limited rows by row id.
+func (b *Builder) limitWhere(cond string) string {
+	if b.limit < 1 || b.dialect == MySQL {
+		return cond
+	}
+
+	id := "rowid"
+	if b.dialect == Postgres {
+		id = "ctid"
+	}
+	return id + " IN (SELECT " + id + " FROM " + qb.Quote(b.table, "") + " WHERE " + cond + " LIMIT " + strconv.Itoa(b.limit) + ")"
+}